@@ -0,0 +1,268 @@
+// Package auth authenticates callers of the core service with static API keys or JWT bearer tokens, and authorises
+// the methods they call by role
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gopkg.in/yaml.v3"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+)
+
+// Roles granted by default
+const (
+	RoleReader = "reader" // reads events
+	RoleWriter = "writer" // updates events
+	RoleAdmin  = "admin"  // manages the configuration of the service
+)
+
+// Errors returned when a caller is not authenticated or not allowed to call a method
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission_denied")
+)
+
+// Config is the layout of an auth file
+type Config struct {
+	APIKeys []APIKey            `yaml:"apiKeys"`
+	JWT     *JWTConfig          `yaml:"jwt"`
+	Roles   map[string][]string `yaml:"roles"`  // methods each role may call, defaults to DefaultRoles
+	Public  []string            `yaml:"public"` // methods anyone may call, defaults to DefaultPublic
+}
+
+// APIKey is a static key identifying a client
+type APIKey struct {
+	Name  string   `yaml:"name"`
+	Key   string   `yaml:"key"`
+	Roles []string `yaml:"roles"`
+}
+
+// JWTConfig configures the verification of JWT bearer tokens
+type JWTConfig struct {
+	JWKSFile   string `yaml:"jwksFile"` // keys tokens are signed with, relative to the auth file
+	Issuer     string `yaml:"issuer"`
+	Audience   string `yaml:"audience"`
+	RolesClaim string `yaml:"rolesClaim"` // claim holding the roles, a list or a space separated string
+}
+
+// DefaultRoles lets readers read events, writers also update them and admins do everything
+func DefaultRoles() map[string][]string {
+	read := []string{
+		core.Service_GetSportEvent_FullMethodName,
+		core.Service_ListSportTypes_FullMethodName,
+	}
+	write := append(slices.Clone(read), core.Service_Update_FullMethodName)
+	return map[string][]string{
+		RoleReader: read,
+		RoleWriter: write,
+		RoleAdmin:  append(slices.Clone(write), core.Service_UpdateSportType_FullMethodName),
+	}
+}
+
+// DefaultPublic are the methods of the health and reflection services, anyone may call them
+func DefaultPublic() []string {
+	return []string{"/grpc.health.v1.Health/*", "/grpc.reflection.*"}
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Name  string
+	Roles []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal authenticated for a request, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Authenticator authenticates and authorises callers
+type Authenticator struct {
+	apiKeys []APIKey
+	jwt     *JWTConfig
+	keys    map[string]any // JWKS keys by key ID
+	roles   map[string][]string
+	public  []string
+}
+
+// Load creates an Authenticator from a YAML auth file
+func Load(path string) (*Authenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config %v: %w", path, err)
+	}
+	if config.JWT != nil && config.JWT.JWKSFile != "" && !filepath.IsAbs(config.JWT.JWKSFile) {
+		config.JWT.JWKSFile = filepath.Join(filepath.Dir(path), config.JWT.JWKSFile)
+	}
+	return New(config)
+}
+
+// New creates an Authenticator
+func New(config Config) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKeys: config.APIKeys,
+		jwt:     config.JWT,
+		roles:   config.Roles,
+		public:  config.Public,
+	}
+	if a.roles == nil {
+		a.roles = DefaultRoles()
+	}
+	if a.public == nil {
+		a.public = DefaultPublic()
+	}
+
+	for _, key := range a.apiKeys {
+		if key.Name == "" || key.Key == "" {
+			return nil, fmt.Errorf("api key %q needs a name and a key", key.Name)
+		}
+		if err := a.checkRoles(key.Roles); err != nil {
+			return nil, fmt.Errorf("api key %v: %w", key.Name, err)
+		}
+	}
+
+	if a.jwt != nil {
+		if a.jwt.JWKSFile == "" {
+			return nil, errors.New("jwt needs a jwksFile")
+		}
+		if a.jwt.RolesClaim == "" {
+			a.jwt.RolesClaim = "roles"
+		}
+		keys, err := LoadJWKS(a.jwt.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+	}
+
+	return a, nil
+}
+
+func (a *Authenticator) checkRoles(roles []string) error {
+	for _, role := range roles {
+		if _, ok := a.roles[role]; !ok {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+// Public reports whether anyone may call a method, without authenticating
+func (a *Authenticator) Public(method string) bool {
+	return slices.ContainsFunc(a.public, func(pattern string) bool {
+		return matches(pattern, method)
+	})
+}
+
+// Authenticate identifies the caller from an API key or the value of an Authorization header
+func (a *Authenticator) Authenticate(apiKey, authorization string) (*Principal, error) {
+	if scheme, token, ok := strings.Cut(authorization, " "); ok {
+		switch strings.ToLower(scheme) {
+		case "bearer":
+			return a.authenticateJWT(token)
+		case "apikey":
+			apiKey = token
+		}
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: no credentials", ErrUnauthenticated)
+	}
+	for _, key := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(apiKey)) == 1 {
+			return &Principal{Name: key.Name, Roles: key.Roles}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+}
+
+// authenticateJWT verifies a bearer token against the JWKS
+func (a *Authenticator) authenticateJWT(token string) (*Principal, error) {
+	if a.jwt == nil {
+		return nil, fmt.Errorf("%w: bearer tokens are not accepted", ErrUnauthenticated)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+	if a.jwt.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.jwt.Issuer))
+	}
+	if a.jwt.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.jwt.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return key, nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	return &Principal{Name: subject, Roles: rolesClaim(claims[a.jwt.RolesClaim])}, nil
+}
+
+// rolesClaim reads roles from a list claim or a space separated string claim
+func rolesClaim(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var roles []string
+		for _, role := range value {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
+
+// Authorize checks that one of the roles of the principal may call a method
+func (a *Authenticator) Authorize(principal *Principal, method string) error {
+	for _, role := range principal.Roles {
+		if slices.ContainsFunc(a.roles[role], func(pattern string) bool { return matches(pattern, method) }) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v may not call %v", ErrPermissionDenied, principal.Name, method)
+}
+
+// matches reports whether a method matches a pattern, a trailing * matches any suffix
+func matches(pattern, method string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return pattern == method
+}
@@ -0,0 +1,203 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+)
+
+// writeAuthConfig writes an auth file with a reader API key and JWT verified against a freshly generated RSA key,
+// returning the path of the file and the key tokens are signed with
+func writeAuthConfig(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	dir := t.TempDir()
+	jwks := fmt.Sprintf(`{"keys": [{"kty": "RSA", "kid": "test", "use": "sig", "n": %q, "e": %q}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	if err := os.WriteFile(filepath.Join(dir, "jwks.json"), []byte(jwks), 0o600); err != nil {
+		t.Fatalf("failed to write jwks: %v", err)
+	}
+
+	config := `
+apiKeys:
+  - name: dashboard
+    key: reader-key
+    roles: [reader]
+jwt:
+  jwksFile: jwks.json
+  issuer: https://issuer.test
+  audience: core
+`
+	path := filepath.Join(dir, "auth.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write auth config: %v", err)
+	}
+	return path, key
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestAuthenticator_APIKeys(t *testing.T) {
+	path, _ := writeAuthConfig(t)
+	authenticator, err := auth.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	principal, err := authenticator.Authenticate("reader-key", "")
+	if err != nil || principal.Name != "dashboard" {
+		t.Fatalf("expected the dashboard key to authenticate, got %v (error %v)", principal, err)
+	}
+	if err := authenticator.Authorize(principal, core.Service_GetSportEvent_FullMethodName); err != nil {
+		t.Fatalf("expected readers to get sport events: %v", err)
+	}
+	if err := authenticator.Authorize(principal, core.Service_Update_FullMethodName); !errors.Is(err,
+		auth.ErrPermissionDenied) {
+		t.Fatalf("expected readers not to update events, got %v", err)
+	}
+
+	if _, err := authenticator.Authenticate("", "ApiKey reader-key"); err != nil {
+		t.Fatalf("expected the key to be accepted in the Authorization header: %v", err)
+	}
+	for _, key := range []string{"", "wrong-key"} {
+		if _, err := authenticator.Authenticate(key, ""); !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Fatalf("expected key %q to be rejected, got %v", key, err)
+		}
+	}
+}
+
+func TestAuthenticator_JWT(t *testing.T) {
+	path, key := writeAuthConfig(t)
+	authenticator, err := auth.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims := jwt.MapClaims{
+		"sub":   "trader",
+		"iss":   "https://issuer.test",
+		"aud":   "core",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"writer"},
+	}
+	principal, err := authenticator.Authenticate("", "Bearer "+sign(t, key, claims))
+	if err != nil || principal.Name != "trader" {
+		t.Fatalf("expected the token to authenticate, got %v (error %v)", principal, err)
+	}
+	if err := authenticator.Authorize(principal, core.Service_Update_FullMethodName); err != nil {
+		t.Fatalf("expected writers to update events: %v", err)
+	}
+	if err := authenticator.Authorize(principal, core.Service_UpdateSportType_FullMethodName); err == nil {
+		t.Fatalf("expected writers not to manage sport types")
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	for name, token := range map[string]string{
+		"expired":        sign(t, key, with(claims, "exp", time.Now().Add(-time.Minute).Unix())),
+		"no expiry":      sign(t, key, with(claims, "exp", nil)),
+		"wrong issuer":   sign(t, key, with(claims, "iss", "https://other.test")),
+		"wrong audience": sign(t, key, with(claims, "aud", "other")),
+		"wrong key":      sign(t, otherKey, claims),
+		"unsigned":       "e30.e30.",
+	} {
+		if _, err := authenticator.Authenticate("", "Bearer "+token); !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Fatalf("expected the %v token to be rejected, got %v", name, err)
+		}
+	}
+}
+
+// with returns a copy of the claims with a claim replaced, or removed when the value is nil
+func with(claims jwt.MapClaims, name string, value any) jwt.MapClaims {
+	out := jwt.MapClaims{}
+	for k, v := range claims {
+		out[k] = v
+	}
+	if value == nil {
+		delete(out, name)
+	} else {
+		out[name] = value
+	}
+	return out
+}
+
+func TestAuthenticator_UnaryServerInterceptor(t *testing.T) {
+	path, _ := writeAuthConfig(t)
+	authenticator, err := auth.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	interceptor := authenticator.UnaryServerInterceptor()
+
+	call := func(method string, md metadata.MD) (*auth.Principal, error) {
+		var principal *auth.Principal
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				principal, _ = auth.PrincipalFromContext(ctx)
+				return nil, nil
+			})
+		return principal, err
+	}
+
+	principal, err := call(core.Service_GetSportEvent_FullMethodName, metadata.Pairs(auth.APIKeyHeader, "reader-key"))
+	if err != nil || principal == nil || principal.Name != "dashboard" {
+		t.Fatalf("expected the call to be authorised, got %v (error %v)", principal, err)
+	}
+	_, err = call(core.Service_Update_FullMethodName, metadata.Pairs(auth.APIKeyHeader, "reader-key"))
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	_, err = call(core.Service_GetSportEvent_FullMethodName, metadata.MD{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+	if _, err := call("/grpc.health.v1.Health/Check", metadata.MD{}); err != nil {
+		t.Fatalf("expected health checks to be public: %v", err)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for name, config := range map[string]auth.Config{
+		"unknown role": {APIKeys: []auth.APIKey{{Name: "a", Key: "k", Roles: []string{"superuser"}}}},
+		"missing key":  {APIKeys: []auth.APIKey{{Name: "a", Roles: []string{auth.RoleReader}}}},
+		"no jwks":      {JWT: &auth.JWTConfig{Issuer: "https://issuer.test"}},
+	} {
+		if _, err := auth.New(config); err == nil {
+			t.Fatalf("expected an error for %v", name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+)
+
+// APIKeyHeader is the metadata key, or HTTP header, carrying an API key
+const APIKeyHeader = "x-api-key"
+
+// UnaryServerInterceptor authenticates the caller of every unary RPC and checks it may call the method
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error,
+	) {
+		ctx, err := a.check(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates the caller of every streaming RPC and checks it may call the method
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.check(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream is a server stream carrying the principal in its context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// check authenticates the caller from the request metadata and authorises the method
+func (a *Authenticator) check(ctx context.Context, method string) (context.Context, error) {
+	if a.Public(method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := a.Authenticate(first(md.Get(APIKeyHeader)), first(md.Get("authorization")))
+	if err != nil {
+		return ctx, statusError(err)
+	}
+	if err := a.Authorize(principal, method); err != nil {
+		return ctx, statusError(err)
+	}
+	return WithPrincipal(logging.WithFields(ctx, logrus.Fields{"principal": principal.Name}), principal), nil
+}
+
+// statusError converts an auth error to a gRPC status
+func statusError(err error) error {
+	if errors.Is(err, ErrPermissionDenied) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+)
+
+// ErrorWriter writes an auth error, as a gRPC status error, in the format of the HTTP API
+type ErrorWriter func(w http.ResponseWriter, err error)
+
+// Middleware authenticates requests to named routes and checks the caller may call the gRPC method the route is named
+// after. Routes without a name are public.
+func (a *Authenticator) Middleware(writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil || route.GetName() == "" || a.Public(route.GetName()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := a.Authenticate(r.Header.Get(APIKeyHeader), r.Header.Get("Authorization"))
+			if err == nil {
+				err = a.Authorize(principal, route.GetName())
+			}
+			if err != nil {
+				writeError(w, statusError(err))
+				return
+			}
+			ctx := logging.WithFields(r.Context(), logrus.Fields{"principal": principal.Name})
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(ctx, principal)))
+		})
+	}
+}
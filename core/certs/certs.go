@@ -0,0 +1,137 @@
+// Package certs serves the TLS certificates of the core servers, reloading them when their files change on disk, and
+// loads those of its clients
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config locates the certificate files
+type Config struct {
+	CertFile     string // PEM certificate chain of the server
+	KeyFile      string // PEM private key of the certificate
+	ClientCAFile string // PEM bundle of the CAs client certificates must be signed by, clients are not verified when empty
+}
+
+// Reloader holds the TLS configuration built from the certificate files, and rebuilds it when they change
+type Reloader struct {
+	config  Config
+	current atomic.Pointer[tls.Config]
+
+	mtx      sync.Mutex // serialises reloads
+	modTimes map[string]time.Time
+}
+
+// New loads the certificate files
+func New(config Config) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("tls needs a certificate and a key file")
+	}
+
+	r := &Reloader{config: config}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig returns the configuration of a server, every handshake uses the certificates last loaded
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+// Reload loads the certificate files again if any of them changed since they were last loaded, reporting whether
+// they did. The last good certificates are kept when the files cannot be loaded.
+func (r *Reloader) Reload() (bool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	modTimes := make(map[string]time.Time, 3)
+	changed := r.modTimes == nil
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, fmt.Errorf("failed_to_read_certificates: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+		changed = changed || !info.ModTime().Equal(r.modTimes[path])
+	}
+	if !changed {
+		return false, nil
+	}
+
+	config, err := r.load()
+	if err != nil {
+		return false, err
+	}
+	r.current.Store(config)
+	r.modTimes = modTimes
+	return true, nil
+}
+
+// load builds the TLS configuration from the certificate files
+func (r *Reloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed_to_load_certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed_to_read_client_ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed_to_load_client_ca: no certificates in %v", r.config.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// Watch reloads the certificates whenever their files change, until the context is cancelled
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				// keep serving the last good certificates until the files are fixed
+				logrus.WithError(err).WithField("cert", r.config.CertFile).Error("certificates_reload_failed")
+				continue
+			}
+			if reloaded {
+				logrus.WithField("cert", r.config.CertFile).Info("certificates_reloaded")
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/certs"
+)
+
+// issuer signs test certificates
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA: %v", err)
+	}
+	return &issuer{cert: cert, key: key}
+}
+
+// issue returns a PEM certificate and key for the common name, usable by servers on 127.0.0.1 and by clients
+func (i *issuer) issue(t *testing.T, name string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("failed to generate serial: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, i.cert, &key.PublicKey, i.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (i *issuer) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: i.cert.Raw})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %v: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to touch %v: %v", path, err)
+	}
+}
+
+// serve starts a TLS server using the reloader and returns a client trusting the CA
+func serve(t *testing.T, reloader *certs.Reloader, ca *issuer, clientCert *tls.Certificate) (
+	*httptest.Server, *http.Client,
+) {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientConfig := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	if clientCert != nil {
+		clientConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+	return server, &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig, DisableKeepAlives: true}}
+}
+
+func servedCommonName(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return resp.TLS.PeerCertificates[0].Subject.CommonName
+}
+
+func TestReloader_Reload(t *testing.T) {
+	ca := newIssuer(t)
+	dir := t.TempDir()
+	config := certs.Config{CertFile: filepath.Join(dir, "server.crt"), KeyFile: filepath.Join(dir, "server.key")}
+	cert, key := ca.issue(t, "first")
+	writeFile(t, config.CertFile, cert, time.Now().Add(-time.Minute))
+	writeFile(t, config.KeyFile, key, time.Now().Add(-time.Minute))
+
+	reloader, err := certs.New(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server, client := serve(t, reloader, ca, nil)
+	if name := servedCommonName(t, client, server.URL); name != "first" {
+		t.Fatalf("expected the first certificate to be served, got %v", name)
+	}
+
+	if reloaded, err := reloader.Reload(); reloaded || err != nil {
+		t.Fatalf("expected no reload while the files are unchanged, got %v (error %v)", reloaded, err)
+	}
+
+	// a broken file keeps the last good certificate
+	writeFile(t, config.CertFile, []byte("not a certificate"), time.Now())
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatalf("expected an error for an invalid certificate")
+	}
+	if name := servedCommonName(t, client, server.URL); name != "first" {
+		t.Fatalf("expected the first certificate to still be served, got %v", name)
+	}
+
+	cert, key = ca.issue(t, "second")
+	writeFile(t, config.CertFile, cert, time.Now())
+	writeFile(t, config.KeyFile, key, time.Now())
+	if reloaded, err := reloader.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected the certificate to be reloaded, got %v (error %v)", reloaded, err)
+	}
+	if name := servedCommonName(t, client, server.URL); name != "second" {
+		t.Fatalf("expected the second certificate to be served, got %v", name)
+	}
+}
+
+func TestReloader_ClientCertificates(t *testing.T) {
+	ca := newIssuer(t)
+	dir := t.TempDir()
+	config := certs.Config{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "clients.pem"),
+	}
+	cert, key := ca.issue(t, "server")
+	writeFile(t, config.CertFile, cert, time.Now())
+	writeFile(t, config.KeyFile, key, time.Now())
+	writeFile(t, config.ClientCAFile, ca.pem(), time.Now())
+
+	reloader, err := certs.New(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server, client := serve(t, reloader, ca, nil)
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatalf("expected a client without a certificate to be rejected")
+	}
+
+	clientPEM, clientKey := ca.issue(t, "client")
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKey)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	server, client = serve(t, reloader, ca, &clientCert)
+	if name := servedCommonName(t, client, server.URL); name != "server" {
+		t.Fatalf("expected the client certificate to be accepted, got %v", name)
+	}
+
+	untrusted := newIssuer(t)
+	otherPEM, otherKey := untrusted.issue(t, "intruder")
+	otherCert, err := tls.X509KeyPair(otherPEM, otherKey)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	server, client = serve(t, reloader, ca, &otherCert)
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatalf("expected a client certificate from another CA to be rejected")
+	}
+}
+
+func TestClientConfig_Credentials(t *testing.T) {
+	ca := newIssuer(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, data, time.Now())
+		return path
+	}
+	serverCert, serverKey := ca.issue(t, "server")
+	clientCert, clientKey := ca.issue(t, "client")
+	reloader, err := certs.New(certs.Config{
+		CertFile:     write("server.crt", serverCert),
+		KeyFile:      write("server.key", serverKey),
+		ClientCAFile: write("ca.pem", ca.pem()),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	// check calls the server with the credentials of a client config
+	check := func(config certs.ClientConfig) error {
+		t.Helper()
+		creds, err := config.Credentials()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	client := certs.ClientConfig{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: write("client.crt", clientCert),
+		KeyFile:  write("client.key", clientKey),
+	}
+	if err := check(client); err != nil {
+		t.Fatalf("expected the client certificate to be accepted, got %v", err)
+	}
+	if err := check(certs.ClientConfig{CAFile: client.CAFile}); err == nil {
+		t.Fatalf("expected a client without a certificate to be rejected")
+	}
+	if err := check(certs.ClientConfig{}); err == nil {
+		t.Fatalf("expected a plaintext client to be rejected")
+	}
+
+	if _, err := (certs.ClientConfig{CertFile: client.CertFile}).Credentials(); err == nil {
+		t.Fatalf("expected an error for a certificate without a key")
+	}
+}
@@ -0,0 +1,57 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ClientConfig locates the files a client verifies the certificate of its server with and, for mTLS, the certificate
+// it presents. The client connects in plaintext when no file is set.
+type ClientConfig struct {
+	CAFile   string `yaml:"caFile"`   // PEM bundle of the CAs the server certificate must be signed by
+	CertFile string `yaml:"certFile"` // PEM certificate presented to the server, for mTLS
+	KeyFile  string `yaml:"keyFile"`  // PEM private key of the certificate
+}
+
+// Enabled reports whether the client connects over TLS
+func (c ClientConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.KeyFile != ""
+}
+
+// Credentials loads the files into the transport credentials of a gRPC client, plaintext when TLS is not enabled.
+// The server is verified against the system roots when no CA file is set.
+func (c ClientConfig) Credentials() (credentials.TransportCredentials, error) {
+	if !c.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("tls client certificate needs both a certificate and a key file")
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed_to_read_ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed_to_load_ca: no certificates in %v", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed_to_load_certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
@@ -0,0 +1,125 @@
+// Package changes notifies subscribers of the changes made to events by successful updates
+package changes
+
+import (
+	"sync"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// DefaultBuffer is the number of changes a subscriber can fall behind by before it is dropped
+const DefaultBuffer = 64
+
+// Change is a change made to an event by an update
+type Change struct {
+	Event *model.Event // the event as stored after the update
+	Delta *model.Event // the values changed by the update, merging it into the previous event gives Event
+}
+
+// Subscription receives the changes made to an event, or to every event
+type Subscription struct {
+	C <-chan *Change // closed when the subscription is closed, or dropped for falling behind
+
+	broker  *Broker
+	eventID string
+	ch      chan *Change
+	once    sync.Once
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// Broker fans changes out to their subscribers. Publishing never blocks: a subscriber that falls behind by more than
+// the buffer is dropped, closing its channel, so it can subscribe again and start from a fresh snapshot.
+type Broker struct {
+	mtx         sync.Mutex
+	buffer      int
+	subscribers map[string]map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroker creates a Broker buffering up to buffer changes per subscriber, DefaultBuffer when not positive
+func NewBroker(buffer int) *Broker {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Broker{buffer: buffer, subscribers: map[string]map[*Subscription]struct{}{}}
+}
+
+// Subscribe subscribes to the changes of an event, or of every event when eventID is empty
+func (b *Broker) Subscribe(eventID string) *Subscription {
+	ch := make(chan *Change, b.buffer)
+	sub := &Subscription{C: ch, broker: b, eventID: eventID, ch: ch}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+	if b.subscribers[eventID] == nil {
+		b.subscribers[eventID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[eventID][sub] = struct{}{}
+	return sub
+}
+
+// Publish sends a change to the subscribers of its event and to the subscribers of every event
+func (b *Broker) Publish(change *Change) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for _, eventID := range []string{change.Event.GetID(), ""} {
+		for sub := range b.subscribers[eventID] {
+			select {
+			case sub.ch <- change:
+			default:
+				b.remove(sub)
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (b *Broker) Subscribers() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	count := 0
+	for _, subs := range b.subscribers {
+		count += len(subs)
+	}
+	return count
+}
+
+// Close closes every subscription, later subscriptions are closed straight away
+func (b *Broker) Close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.remove(sub)
+}
+
+// remove removes a subscription and closes its channel, the lock must be held
+func (b *Broker) remove(sub *Subscription) {
+	if subs := b.subscribers[sub.eventID]; subs != nil {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(b.subscribers, sub.eventID)
+		}
+	}
+	sub.once.Do(func() { close(sub.ch) })
+}
@@ -0,0 +1,70 @@
+package changes_test
+
+import (
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/changes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func change(id string) *changes.Change {
+	return &changes.Change{Event: &model.Event{ID: id}, Delta: &model.Event{ID: id}}
+}
+
+func TestBroker_Routing(t *testing.T) {
+	broker := changes.NewBroker(4)
+	one := broker.Subscribe("evt-1")
+	all := broker.Subscribe("")
+	defer one.Close()
+	defer all.Close()
+
+	broker.Publish(change("evt-1"))
+	broker.Publish(change("evt-2"))
+
+	if got := (<-one.C).Event.GetID(); got != "evt-1" {
+		t.Fatalf("expected the change of evt-1, got %v", got)
+	}
+	select {
+	case c := <-one.C:
+		t.Fatalf("expected no change of other events, got %v", c.Event.GetID())
+	default:
+	}
+
+	for _, want := range []string{"evt-1", "evt-2"} {
+		if got := (<-all.C).Event.GetID(); got != want {
+			t.Fatalf("expected the change of %v, got %v", want, got)
+		}
+	}
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := changes.NewBroker(1)
+	sub := broker.Subscribe("evt-1")
+
+	broker.Publish(change("evt-1"))
+	broker.Publish(change("evt-1"))
+
+	if _, ok := <-sub.C; !ok {
+		t.Fatalf("expected the buffered change to be delivered")
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatalf("expected the subscription to be closed once it fell behind")
+	}
+	if broker.Subscribers() != 0 {
+		t.Fatalf("expected the subscriber to be removed, got %d", broker.Subscribers())
+	}
+	sub.Close() // closing a dropped subscription is a no-op
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := changes.NewBroker(0)
+	sub := broker.Subscribe("evt-1")
+	broker.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Fatalf("expected the subscription to be closed")
+	}
+	if _, ok := <-broker.Subscribe("evt-1").C; ok {
+		t.Fatalf("expected subscriptions to a closed broker to be closed")
+	}
+}
@@ -11,12 +11,20 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/encoding/proto"
 
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/certs"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/changes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/config"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ratelimit"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/scheduler"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/service"
-	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
-	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/sporttransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/tracing"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
 )
 
@@ -35,26 +43,145 @@ func main() {
 	app.Version = fmt.Sprintf("%v", Version)
 	app.Usage = "Core"
 	app.Description = "Runs Transformations on the Core system model, persists and exposes the data via an API"
-	app.Action = func(_ *cli.Context) error {
-		log.SetFormatter(&log.TextFormatter{})
+	app.Flags = config.Flags()
+	app.Action = func(c *cli.Context) error {
+		cfg, err := config.Load(c)
+		if err != nil {
+			return err
+		}
+		cfg.ConfigureLogging()
+
+		var authenticator *auth.Authenticator
+		if cfg.Auth != "" {
+			authenticator, err = auth.Load(cfg.Auth)
+			if err != nil {
+				return err
+			}
+		}
 
-		// technically the address and password should be put in config and .env file,
-		// but for the purpose of the technical test, it's okay to leave them here
-		repo, err := repository.NewRedisRepository(context.Background(), "localhost:6379", "")
+		shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName: "core",
+			Exporter:    cfg.Tracing.Exporter,
+			Endpoint:    cfg.Tracing.Endpoint,
+			Insecure:    cfg.Tracing.Insecure,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
 		if err != nil {
 			return err
 		}
+		defer func() {
+			// flush the spans still buffered, including those of the requests drained on shutdown
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.WithError(err).Warn("tracing_shutdown_error")
+			}
+		}()
+
+		startupCtx, cancelStartup := context.WithTimeout(context.Background(), cfg.Timeouts.Startup)
+		defer cancelStartup()
+		compression, err := repository.ParseCompression(cfg.Repository.Compression)
+		if err != nil {
+			return err
+		}
+		repo, err := repository.NewRedisRepository(startupCtx, cfg.Repository.Address, cfg.Repository.Password,
+			compression)
+		if err != nil {
+			return err
+		}
+		repo = metrics.InstrumentRepository(tracing.TraceRepository(repo))
+
+		var limiter *ratelimit.Limiter
+		if cfg.RateLimits.Enabled() {
+			limits := make(map[string]ratelimit.Limit, len(cfg.RateLimits.Methods))
+			for method, limit := range cfg.RateLimits.Methods {
+				limits[method] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+			}
+			limiter = ratelimit.New(ratelimit.Config{
+				Methods:       limits,
+				MaxConcurrent: cfg.RateLimits.MaxConcurrent,
+				LatencyTarget: cfg.RateLimits.LatencyTarget,
+			})
+			repo = ratelimit.ObserveRepository(repo, limiter)
+		}
+
+		sportTypes := sporttypes.NewRegistry(sporttypes.Defaults()...)
+		if cfg.SportTypes != "" {
+			sportTypes, err = sporttypes.LoadRegistry(cfg.SportTypes)
+			if err != nil {
+				return err
+			}
+		}
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go sportTypes.Watch(watchCtx, 5*time.Second)
+
+		pipeline, conns, err := newPipeline(cfg, sportTypes)
+		defer func() {
+			for _, conn := range conns {
+				if err := conn.Close(); err != nil {
+					log.WithError(err).WithField("target", conn.Target()).Warn("remote_transform_close_error")
+				}
+			}
+		}()
+		if err != nil {
+			return err
+		}
+
+		mergerClient := merger.NewInlineMergerClient()
+		if cfg.MergerAddress != "" {
+			creds, err := certs.ClientConfig{
+				CAFile:   cfg.MergerTLS.CAFile,
+				CertFile: cfg.MergerTLS.CertFile,
+				KeyFile:  cfg.MergerTLS.KeyFile,
+			}.Credentials()
+			if err != nil {
+				return err
+			}
+			var conn *grpc.ClientConn
+			mergerClient, conn, err = merger.Dial(merger.Config{
+				Address:     cfg.MergerAddress,
+				Timeout:     cfg.Timeouts.Merge,
+				Credentials: creds,
+			})
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := conn.Close(); err != nil {
+					log.WithError(err).Warn("merger_close_error")
+				}
+			}()
+		}
 
 		upstreams := &service.Upstreams{
-			MergerClient: merger.NewInlineMergerClient(),
+			MergerClient: metrics.InstrumentMerger(tracing.TraceMerger(mergerClient)),
 			Repo:         repo,
-			Transforms: []transforms.TransformClient{
-				sporttransform.NewSportTransformClient(),
-			},
+			Pipeline:     pipeline,
+			SportTypes:   sportTypes,
+			Changes:      changes.NewBroker(changes.DefaultBuffer),
 		}
 
 		// Run the service as a goroutine, watching for errors
-		svc := service.NewService(50051, 8080, upstreams)
+		svc := service.NewService(cfg.GRPCPort, cfg.HTTPPort, upstreams)
+		svc.UpdateTimeout = cfg.Timeouts.Update
+		svc.DrainDelay = cfg.Timeouts.Drain
+		svc.Auth = authenticator
+		svc.RateLimiter = limiter
+		if cfg.TLS.Enabled() {
+			svc.TLS, err = certs.New(certs.Config{
+				CertFile:     cfg.TLS.CertFile,
+				KeyFile:      cfg.TLS.KeyFile,
+				ClientCAFile: cfg.TLS.ClientCAFile,
+			})
+			if err != nil {
+				return err
+			}
+			go svc.TLS.Watch(watchCtx, 5*time.Second)
+		}
+		if cfg.StaleUpdates == config.StaleUpdatesPartial {
+			svc.StaleUpdatePolicy = service.StaleUpdatePartial
+		}
 		errChan := make(chan error, 1)
 		go func() {
 			if err := svc.Run(); err != nil {
@@ -62,6 +189,18 @@ func main() {
 			}
 		}()
 
+		// Stop betting on events as they start
+		schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+		defer stopScheduler()
+		schedulerDone := make(chan struct{})
+		go func() {
+			defer close(schedulerDone)
+			scheduler.NewScheduler(repo, svc, scheduler.Config{
+				Offsets:       cfg.Scheduler.Offsets,
+				DefaultOffset: cfg.Scheduler.DefaultOffset,
+			}).Run(schedulerCtx)
+		}()
+
 		// Wait for the signal to die
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals,
@@ -77,8 +216,17 @@ func main() {
 			log.WithField("signal", sig).Warn("shutdown_signal")
 		}
 
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(10))
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
 		defer cancel()
+
+		// no new synthetic updates once shutting down, wait for the one being sent if any
+		stopScheduler()
+		select {
+		case <-schedulerDone:
+		case <-shutdownCtx.Done():
+			log.Warn("scheduler_stop_timed_out")
+		}
+
 		if err := svc.Stop(shutdownCtx); err != nil {
 			log.WithError(err).Warn("shutdown_error")
 		}
@@ -87,6 +235,7 @@ func main() {
 	}
 
 	if err := app.Run(os.Args); err != nil {
+		log.WithError(err).Error("core_failed")
 		os.Exit(-1)
 	}
 }
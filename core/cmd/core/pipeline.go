@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/config"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ladder"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/tracing"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/laddertransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/overroundtransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/pricehistorytransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/remotetransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/ruletransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/sporttransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/statusrolluptransform"
+)
+
+// newPipeline builds the transform pipeline from the enabled built-in transforms, the trading rules and the remote
+// transforms. The connections to the remote transforms are returned to be closed on shutdown.
+func newPipeline(cfg config.Config, sportTypes *sporttypes.Registry) (
+	*transforms.Pipeline, []*grpc.ClientConn, error,
+) {
+	ladderConfig := laddertransform.Config{MinPrice: cfg.Ladder.MinPrice}
+	if cfg.Ladder.File != "" {
+		l, err := ladder.Load(cfg.Ladder.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		ladderConfig.Ladder = l
+	}
+	if cfg.Ladder.BelowMinimum == config.BelowMinimumReject {
+		ladderConfig.Policy = laddertransform.RejectBelowMinimumPrice
+	}
+
+	builtins := []transforms.Stage{
+		{
+			Transform: sporttransform.NewSportTransformClientWithConfig(sporttransform.Config{
+				Registry:     sportTypes,
+				ReapplyNames: cfg.ReapplySports,
+			}),
+		},
+		{
+			Transform: laddertransform.NewLadderTransformClient(ladderConfig),
+		},
+		{
+			Transform: pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{}),
+			DependsOn: []string{"LadderTransform"},
+		},
+		{
+			Transform: overroundtransform.NewOverroundTransformClient(overroundtransform.Config{
+				MinMargin:   cfg.Overround.MinMargin,
+				MaxMargin:   cfg.Overround.MaxMargin,
+				AutoSuspend: cfg.Overround.AutoSuspend,
+			}),
+			DependsOn: []string{"LadderTransform"},
+		},
+	}
+	rollup := transforms.Stage{
+		Transform: statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.Config{
+			CascadeClose:              cfg.StatusRollup.CascadeClose,
+			CascadeSuspend:            cfg.StatusRollup.CascadeSuspend,
+			CloseWhenAllMarketsClosed: cfg.StatusRollup.CloseWhenAllMarketsClosed,
+		}),
+		DependsOn:   []string{"OverroundTransform"}, // rolls up market statuses once they are final
+		WithOutputs: true,                           // including the statuses set by the transforms
+	}
+
+	stages := builtins
+	if cfg.Rules != "" {
+		ruleTransform, err := ruletransform.NewRuleTransformClientFromFile(cfg.Rules)
+		if err != nil {
+			return nil, nil, err
+		}
+		stages = append(stages, transforms.Stage{
+			Transform: ruleTransform,
+			DependsOn: []string{"OverroundTransform"}, // rules are checked on load, one failing is skipped on its own
+		})
+		rollup.DependsOn = append(rollup.DependsOn, ruleTransform.GetName())
+	}
+
+	var conns []*grpc.ClientConn
+	if cfg.RemoteTransforms != "" {
+		remotes, err := remotetransform.LoadConfigs(cfg.RemoteTransforms)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, remote := range remotes {
+			remoteTransform, conn, err := remotetransform.Dial(remote)
+			if err != nil {
+				return nil, conns, err
+			}
+			conns = append(conns, conn)
+			stage, err := remote.Stage(remoteTransform)
+			if err != nil {
+				return nil, conns, err
+			}
+			stages = append(stages, stage)
+		}
+	}
+	stages = append(stages, rollup)
+
+	stages, err := enabledStages(cfg, append(builtins, rollup), stages)
+	if err != nil {
+		return nil, conns, err
+	}
+	for i := range stages {
+		stages[i].Transform = metrics.InstrumentTransform(tracing.TraceTransform(stages[i].Transform))
+	}
+	pipeline, err := transforms.NewPipeline(stages...)
+	if err != nil {
+		return nil, conns, err
+	}
+	return pipeline, conns, metrics.RegisterPipeline(pipeline)
+}
+
+// enabledStages drops the built-in transforms that are not enabled, along with the dependencies on them
+func enabledStages(cfg config.Config, builtins, stages []transforms.Stage) ([]transforms.Stage, error) {
+	var names, disabled []string
+	for _, stage := range builtins {
+		name := stage.Transform.GetName()
+		names = append(names, name)
+		if !cfg.TransformEnabled(name) {
+			disabled = append(disabled, name)
+		}
+	}
+	for _, name := range cfg.Transforms {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("transforms: unknown transform %q, expected one of %v", name, names)
+		}
+	}
+
+	var enabled []transforms.Stage
+	for _, stage := range stages {
+		if slices.Contains(disabled, stage.Transform.GetName()) {
+			continue
+		}
+		stage.DependsOn = slices.DeleteFunc(slices.Clone(stage.DependsOn), func(dep string) bool {
+			return slices.Contains(disabled, dep)
+		})
+		enabled = append(enabled, stage)
+	}
+	return enabled, nil
+}
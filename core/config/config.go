@@ -0,0 +1,692 @@
+// Package config holds the configuration of the core service. Values are read from an optional YAML file and can be
+// overridden by command line flags or their environment variables.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/tracing"
+)
+
+// Repository backends
+const (
+	BackendRedis = "redis"
+)
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Policies for prices below the minimum price of the ladder, see laddertransform.BelowMinimumPolicy
+const (
+	BelowMinimumFlag   = "flag"
+	BelowMinimumReject = "reject"
+)
+
+// Stale update policies, see service.StaleUpdatePolicy
+const (
+	StaleUpdatesDrop    = "drop"
+	StaleUpdatesPartial = "partial"
+)
+
+// Config is the configuration of the core service
+type Config struct {
+	Repository       Repository   `yaml:"repository"`
+	GRPCPort         int          `yaml:"grpcPort"`
+	HTTPPort         int          `yaml:"httpPort"`
+	Log              Log          `yaml:"log"`
+	Transforms       []string     `yaml:"transforms"`       // built-in transforms to run, all of them when empty
+	SportTypes       string       `yaml:"sportTypes"`       // sport types file
+	ReapplySports    bool         `yaml:"reapplySports"`    // overwrite sport names that differ from the sport types
+	Rules            string       `yaml:"rules"`            // trading rules file
+	RemoteTransforms string       `yaml:"remoteTransforms"` // remote transforms file
+	MergerAddress    string       `yaml:"mergerAddress"`    // Merger service, events are merged in process when empty
+	Auth             string       `yaml:"auth"`             // API keys, JWT and roles file, the API is open when empty
+	StaleUpdates     string       `yaml:"staleUpdates"`
+	Timeouts         Timeouts     `yaml:"timeouts"`
+	Tracing          Tracing      `yaml:"tracing"`
+	TLS              TLS          `yaml:"tls"`
+	RateLimits       RateLimits   `yaml:"rateLimits"`
+	Scheduler        Scheduler    `yaml:"scheduler"`
+	Overround        Overround    `yaml:"overround"`
+	Ladder           Ladder       `yaml:"ladder"`
+	StatusRollup     StatusRollup `yaml:"statusRollup"`
+	MergerTLS        ClientTLS    `yaml:"mergerTLS"` // connection to the Merger service, plaintext when unset
+}
+
+// Repository configures where events are stored
+type Repository struct {
+	Backend     string `yaml:"backend"`
+	Address     string `yaml:"address"`
+	Password    string `yaml:"password"`
+	Compression string `yaml:"compression"` // none, snappy or zstd, events are read whatever they were written with
+}
+
+// Log configures logging
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Tracing configures where traces are exported to
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"` // none, stdout or otlp
+	Endpoint    string  `yaml:"endpoint"` // OTLP gRPC endpoint
+	Insecure    bool    `yaml:"insecure"` // connect to the OTLP endpoint without TLS
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// TLS configures the certificates of the gRPC and HTTP servers, which run plaintext when no certificate is set.
+// The files are reloaded when they change.
+type TLS struct {
+	CertFile     string `yaml:"certFile"`
+	KeyFile      string `yaml:"keyFile"`
+	ClientCAFile string `yaml:"clientCAFile"` // CA bundle client certificates are verified against, for mTLS
+}
+
+// Enabled reports whether the servers use TLS
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// ClientTLS configures how a client connects over TLS, see certs.ClientConfig
+type ClientTLS struct {
+	CAFile   string `yaml:"caFile"`   // CA bundle the server certificate is verified against, the system roots when empty
+	CertFile string `yaml:"certFile"` // client certificate, for mTLS
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// RateLimits configures the limits of every client and the shedding of requests when the repository slows down
+type RateLimits struct {
+	Methods       map[string]RateLimit `yaml:"methods"`       // by full gRPC method name, e.g. /core.Service/Update
+	MaxConcurrent int                  `yaml:"maxConcurrent"` // requests handled at once, unbounded when zero
+	LatencyTarget time.Duration        `yaml:"latencyTarget"` // repository latency above which maxConcurrent shrinks
+}
+
+// RateLimit is the token bucket of a client for a method
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`  // requests per second
+	Burst int     `yaml:"burst"` // requests allowed at once
+}
+
+// Enabled reports whether requests are limited at all
+func (r RateLimits) Enabled() bool {
+	return len(r.Methods) > 0 || r.MaxConcurrent > 0
+}
+
+// Ladder configures the price ladder selection prices are snapped onto
+type Ladder struct {
+	File         string  `yaml:"file"`         // ladder file, the Betfair ladder is used when empty
+	MinPrice     float64 `yaml:"minPrice"`     // defaults to the lowest price of the ladder
+	BelowMinimum string  `yaml:"belowMinimum"` // what to do with prices below minPrice
+}
+
+// Overround configures the margin bounds markets are validated against, see overroundtransform.Config
+type Overround struct {
+	MinMargin   float64 `yaml:"minMargin"`   // markets below this margin are warned about
+	MaxMargin   float64 `yaml:"maxMargin"`   // markets above this margin are warned about, zero disables the check
+	AutoSuspend bool    `yaml:"autoSuspend"` // suspend open markets below minMargin
+}
+
+// StatusRollup configures the hierarchy rules enforced between an event, its markets and their selections, see
+// statusrolluptransform.Config
+type StatusRollup struct {
+	CascadeClose              bool `yaml:"cascadeClose"`              // closing closes everything below
+	CascadeSuspend            bool `yaml:"cascadeSuspend"`            // suspending suspends everything open below
+	CloseWhenAllMarketsClosed bool `yaml:"closeWhenAllMarketsClosed"` // the event closes with its last market
+}
+
+// Scheduler configures when betting stops on events as they start
+type Scheduler struct {
+	Offsets       map[string]time.Duration `yaml:"offsets"`       // from StartTime by EventTypeID, negative is before
+	DefaultOffset time.Duration            `yaml:"defaultOffset"` // for event types without an entry in offsets
+}
+
+// Timeouts bound how long the service waits on itself and its upstreams
+type Timeouts struct {
+	Startup  time.Duration `yaml:"startup"`  // connecting to the repository
+	Update   time.Duration `yaml:"update"`   // handling an Update, unbounded when zero
+	Shutdown time.Duration `yaml:"shutdown"` // stopping the service
+	Drain    time.Duration `yaml:"drain"`    // reporting not ready before stopping, for load balancers to drain
+	Merge    time.Duration `yaml:"merge"`    // each call to the Merger service
+}
+
+// Default returns the configuration used for anything that is not configured
+func Default() Config {
+	return Config{
+		Repository: Repository{
+			Backend:     BackendRedis,
+			Address:     "localhost:6379",
+			Compression: repository.CompressionNone.String(),
+		},
+		GRPCPort:     50051,
+		HTTPPort:     8080,
+		Log:          Log{Level: logrus.InfoLevel.String(), Format: LogFormatText},
+		StaleUpdates: StaleUpdatesDrop,
+		Ladder:       Ladder{BelowMinimum: BelowMinimumFlag},
+		StatusRollup: StatusRollup{CascadeClose: true, CascadeSuspend: true, CloseWhenAllMarketsClosed: true},
+		Tracing:      Tracing{Exporter: tracing.ExporterNone, SampleRatio: 1},
+		Timeouts: Timeouts{
+			Startup:  10 * time.Second,
+			Shutdown: 10 * time.Second,
+			Merge:    500 * time.Millisecond,
+		},
+	}
+}
+
+// Flags returns the command line flags overriding the configuration file
+func Flags() []cli.Flag {
+	defaults := Default()
+	return []cli.Flag{
+		cli.StringFlag{Name: "config", EnvVar: "CORE_CONFIG", Usage: "YAML configuration file"},
+		cli.StringFlag{
+			Name:   "repository-backend",
+			EnvVar: "CORE_REPOSITORY_BACKEND",
+			Value:  defaults.Repository.Backend,
+			Usage:  "repository backend storing events (redis)",
+		},
+		cli.StringFlag{
+			Name:   "repository-address",
+			EnvVar: "CORE_REPOSITORY_ADDRESS",
+			Value:  defaults.Repository.Address,
+			Usage:  "address of the repository backend",
+		},
+		cli.StringFlag{
+			Name:   "repository-password",
+			EnvVar: "CORE_REPOSITORY_PASSWORD",
+			Usage:  "password of the repository backend",
+		},
+		cli.StringFlag{
+			Name:   "repository-compression",
+			EnvVar: "CORE_REPOSITORY_COMPRESSION",
+			Value:  defaults.Repository.Compression,
+			Usage:  "compression of the events written to the repository (none, snappy or zstd)",
+		},
+		cli.IntFlag{Name: "grpc-port", EnvVar: "CORE_GRPC_PORT", Value: defaults.GRPCPort, Usage: "gRPC server port"},
+		cli.IntFlag{Name: "http-port", EnvVar: "CORE_HTTP_PORT", Value: defaults.HTTPPort, Usage: "HTTP server port"},
+		cli.StringFlag{
+			Name:   "log-level",
+			EnvVar: "APP_LOG_LEVEL",
+			Value:  defaults.Log.Level,
+			Usage:  "log level (trace, debug, info, warn, error)",
+		},
+		cli.StringFlag{
+			Name:   "log-format",
+			EnvVar: "APP_LOG_FORMAT",
+			Value:  defaults.Log.Format,
+			Usage:  "log format (text, json)",
+		},
+		cli.StringSliceFlag{
+			Name:   "transforms",
+			EnvVar: "CORE_TRANSFORMS",
+			Usage:  "built-in transforms to run, all of them when unset",
+		},
+		cli.StringFlag{
+			Name:   "sport-types",
+			EnvVar: "CORE_SPORT_TYPES",
+			Usage:  "YAML file mapping event types to sports, reloaded when it changes",
+		},
+		cli.BoolFlag{
+			Name:   "reapply-sports",
+			EnvVar: "CORE_REAPPLY_SPORTS",
+			Usage:  "overwrite sport names that differ from the sport types, otherwise only missing names are set",
+		},
+		cli.StringFlag{
+			Name:   "rules",
+			EnvVar: "CORE_RULES",
+			Usage:  "YAML file of trading rules applied to every update",
+		},
+		cli.StringFlag{
+			Name:   "remote-transforms",
+			EnvVar: "CORE_REMOTE_TRANSFORMS",
+			Usage:  "YAML file of out-of-process transforms called over gRPC",
+		},
+		cli.StringFlag{
+			Name:   "auth",
+			EnvVar: "CORE_AUTH",
+			Usage:  "YAML file of API keys, JWT verification and role permissions, the API is open when unset",
+		},
+		cli.StringFlag{
+			Name:   "merger-address",
+			EnvVar: "CORE_MERGER_ADDRESS",
+			Usage:  "address of a Merger gRPC service, events are merged in process when unset",
+		},
+		cli.StringFlag{
+			Name:   "merger-tls-ca-file",
+			EnvVar: "CORE_MERGER_TLS_CA_FILE",
+			Usage:  "PEM CA bundle the Merger service certificate must be signed by, connects over TLS when set",
+		},
+		cli.StringFlag{
+			Name:   "merger-tls-cert-file",
+			EnvVar: "CORE_MERGER_TLS_CERT_FILE",
+			Usage:  "PEM client certificate presented to the Merger service, for mTLS",
+		},
+		cli.StringFlag{
+			Name:   "merger-tls-key-file",
+			EnvVar: "CORE_MERGER_TLS_KEY_FILE",
+			Usage:  "PEM private key of the Merger client certificate",
+		},
+		cli.StringFlag{
+			Name:   "stale-updates",
+			EnvVar: "CORE_STALE_UPDATES",
+			Value:  defaults.StaleUpdates,
+			Usage:  "what to do with updates older than the last one applied (drop, partial)",
+		},
+		cli.StringFlag{
+			Name:   "tracing-exporter",
+			EnvVar: "CORE_TRACING_EXPORTER",
+			Value:  defaults.Tracing.Exporter,
+			Usage:  "where traces are exported to (none, stdout, otlp)",
+		},
+		cli.StringFlag{
+			Name:   "tracing-endpoint",
+			EnvVar: "CORE_TRACING_ENDPOINT",
+			Usage:  "OTLP gRPC endpoint traces are exported to, defaults to OTEL_EXPORTER_OTLP_ENDPOINT",
+		},
+		cli.BoolFlag{
+			Name:   "tracing-insecure",
+			EnvVar: "CORE_TRACING_INSECURE",
+			Usage:  "connect to the OTLP endpoint without TLS",
+		},
+		cli.StringFlag{
+			Name:   "tls-cert-file",
+			EnvVar: "CORE_TLS_CERT_FILE",
+			Usage:  "PEM certificate of the gRPC and HTTP servers, they run plaintext when unset",
+		},
+		cli.StringFlag{Name: "tls-key-file", EnvVar: "CORE_TLS_KEY_FILE", Usage: "PEM private key of the certificate"},
+		cli.StringFlag{
+			Name:   "tls-client-ca-file",
+			EnvVar: "CORE_TLS_CLIENT_CA_FILE",
+			Usage:  "PEM CA bundle client certificates must be signed by, clients are not verified when unset",
+		},
+		cli.Float64Flag{
+			Name:   "tracing-sample-ratio",
+			EnvVar: "CORE_TRACING_SAMPLE_RATIO",
+			Value:  defaults.Tracing.SampleRatio,
+			Usage:  "share of new traces recorded, between 0 and 1",
+		},
+		cli.StringFlag{
+			Name:   "ladder",
+			EnvVar: "CORE_LADDER",
+			Usage:  "YAML file of the price ladder prices are snapped onto, the Betfair ladder when unset",
+		},
+		cli.Float64Flag{
+			Name:   "ladder-min-price",
+			EnvVar: "CORE_LADDER_MIN_PRICE",
+			Usage:  "minimum price, defaults to the lowest price of the ladder",
+		},
+		cli.StringFlag{
+			Name:   "ladder-below-minimum",
+			EnvVar: "CORE_LADDER_BELOW_MINIMUM",
+			Value:  defaults.Ladder.BelowMinimum,
+			Usage:  "what to do with prices below the minimum price (flag, reject)",
+		},
+		cli.Float64Flag{
+			Name:   "overround-min-margin",
+			EnvVar: "CORE_OVERROUND_MIN_MARGIN",
+			Usage:  "margin markets are warned about below, e.g 0.02 for a 102% book",
+		},
+		cli.Float64Flag{
+			Name:   "overround-max-margin",
+			EnvVar: "CORE_OVERROUND_MAX_MARGIN",
+			Usage:  "margin markets are warned about above, unchecked when unset",
+		},
+		cli.BoolFlag{
+			Name:   "overround-auto-suspend",
+			EnvVar: "CORE_OVERROUND_AUTO_SUSPEND",
+			Usage:  "suspend open markets below the minimum margin",
+		},
+		cli.BoolTFlag{
+			Name:   "status-rollup-cascade-close",
+			EnvVar: "CORE_STATUS_ROLLUP_CASCADE_CLOSE",
+			Usage:  "close the markets and selections of a closed event or market",
+		},
+		cli.BoolTFlag{
+			Name:   "status-rollup-cascade-suspend",
+			EnvVar: "CORE_STATUS_ROLLUP_CASCADE_SUSPEND",
+			Usage:  "suspend the open markets and selections of a suspended event or market",
+		},
+		cli.BoolTFlag{
+			Name:   "status-rollup-close-event",
+			EnvVar: "CORE_STATUS_ROLLUP_CLOSE_EVENT",
+			Usage:  "close an event once every one of its markets is closed",
+		},
+		cli.StringSliceFlag{
+			Name:   "scheduler-offsets",
+			EnvVar: "CORE_SCHEDULER_OFFSETS",
+			Usage:  "offsets from the start time at which betting stops, by event type, e.g horse_racing=-1m",
+		},
+		cli.DurationFlag{
+			Name:   "scheduler-default-offset",
+			EnvVar: "CORE_SCHEDULER_DEFAULT_OFFSET",
+			Usage:  "offset from the start time at which betting stops for event types without an offset",
+		},
+		cli.DurationFlag{
+			Name:   "startup-timeout",
+			EnvVar: "CORE_STARTUP_TIMEOUT",
+			Value:  defaults.Timeouts.Startup,
+			Usage:  "time allowed to connect to the repository",
+		},
+		cli.DurationFlag{
+			Name:   "update-timeout",
+			EnvVar: "CORE_UPDATE_TIMEOUT",
+			Usage:  "time allowed to handle an Update, unbounded when unset",
+		},
+		cli.DurationFlag{
+			Name:   "shutdown-timeout",
+			EnvVar: "CORE_SHUTDOWN_TIMEOUT",
+			Value:  defaults.Timeouts.Shutdown,
+			Usage:  "time allowed to stop the service",
+		},
+		cli.DurationFlag{
+			Name:   "merge-timeout",
+			EnvVar: "CORE_MERGE_TIMEOUT",
+			Value:  defaults.Timeouts.Merge,
+			Usage:  "time allowed for each call to the Merger service",
+		},
+		cli.DurationFlag{
+			Name:   "drain-delay",
+			EnvVar: "CORE_DRAIN_DELAY",
+			Usage:  "time the service reports not ready before stopping, for load balancers to drain",
+		},
+		cli.IntFlag{
+			Name:   "max-concurrent-requests",
+			EnvVar: "CORE_MAX_CONCURRENT_REQUESTS",
+			Usage:  "requests handled at once before new ones are shed, unbounded when unset",
+		},
+		cli.DurationFlag{
+			Name:   "latency-target",
+			EnvVar: "CORE_LATENCY_TARGET",
+			Usage:  "repository latency above which fewer requests are handled at once",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the configuration file and the flags that are set, in increasing
+// order of precedence, and validates it
+func Load(c *cli.Context) (Config, error) {
+	config := Default()
+
+	if path := c.String("config"); path != "" {
+		if err := config.readFile(path); err != nil {
+			return config, err
+		}
+	}
+
+	stringFlags := map[string]*string{
+		"repository-backend":     &config.Repository.Backend,
+		"repository-address":     &config.Repository.Address,
+		"repository-password":    &config.Repository.Password,
+		"repository-compression": &config.Repository.Compression,
+		"log-level":              &config.Log.Level,
+		"log-format":             &config.Log.Format,
+		"sport-types":            &config.SportTypes,
+		"rules":                  &config.Rules,
+		"ladder":                 &config.Ladder.File,
+		"ladder-below-minimum":   &config.Ladder.BelowMinimum,
+		"remote-transforms":      &config.RemoteTransforms,
+		"merger-address":         &config.MergerAddress,
+		"merger-tls-ca-file":     &config.MergerTLS.CAFile,
+		"merger-tls-cert-file":   &config.MergerTLS.CertFile,
+		"merger-tls-key-file":    &config.MergerTLS.KeyFile,
+		"auth":                   &config.Auth,
+		"stale-updates":          &config.StaleUpdates,
+		"tracing-exporter":       &config.Tracing.Exporter,
+		"tracing-endpoint":       &config.Tracing.Endpoint,
+		"tls-cert-file":          &config.TLS.CertFile,
+		"tls-key-file":           &config.TLS.KeyFile,
+		"tls-client-ca-file":     &config.TLS.ClientCAFile,
+	}
+	for name, value := range stringFlags {
+		if c.IsSet(name) {
+			*value = c.String(name)
+		}
+	}
+	intFlags := map[string]*int{
+		"grpc-port":               &config.GRPCPort,
+		"http-port":               &config.HTTPPort,
+		"max-concurrent-requests": &config.RateLimits.MaxConcurrent,
+	}
+	for name, value := range intFlags {
+		if c.IsSet(name) {
+			*value = c.Int(name)
+		}
+	}
+	durationFlags := map[string]*time.Duration{
+		"startup-timeout":          &config.Timeouts.Startup,
+		"update-timeout":           &config.Timeouts.Update,
+		"shutdown-timeout":         &config.Timeouts.Shutdown,
+		"drain-delay":              &config.Timeouts.Drain,
+		"merge-timeout":            &config.Timeouts.Merge,
+		"latency-target":           &config.RateLimits.LatencyTarget,
+		"scheduler-default-offset": &config.Scheduler.DefaultOffset,
+	}
+	for name, value := range durationFlags {
+		if c.IsSet(name) {
+			*value = c.Duration(name)
+		}
+	}
+	if c.IsSet("reapply-sports") {
+		config.ReapplySports = c.Bool("reapply-sports")
+	}
+	if c.IsSet("tracing-insecure") {
+		config.Tracing.Insecure = c.Bool("tracing-insecure")
+	}
+	if c.IsSet("tracing-sample-ratio") {
+		config.Tracing.SampleRatio = c.Float64("tracing-sample-ratio")
+	}
+	if c.IsSet("ladder-min-price") {
+		config.Ladder.MinPrice = c.Float64("ladder-min-price")
+	}
+	if c.IsSet("overround-min-margin") {
+		config.Overround.MinMargin = c.Float64("overround-min-margin")
+	}
+	if c.IsSet("overround-max-margin") {
+		config.Overround.MaxMargin = c.Float64("overround-max-margin")
+	}
+	if c.IsSet("overround-auto-suspend") {
+		config.Overround.AutoSuspend = c.Bool("overround-auto-suspend")
+	}
+	boolTFlags := map[string]*bool{
+		"status-rollup-cascade-close":   &config.StatusRollup.CascadeClose,
+		"status-rollup-cascade-suspend": &config.StatusRollup.CascadeSuspend,
+		"status-rollup-close-event":     &config.StatusRollup.CloseWhenAllMarketsClosed,
+	}
+	for name, value := range boolTFlags {
+		if c.IsSet(name) {
+			*value = c.BoolT(name)
+		}
+	}
+	if c.IsSet("transforms") {
+		config.Transforms = c.StringSlice("transforms")
+	}
+	if c.IsSet("scheduler-offsets") {
+		offsets, err := parseOffsets(c.StringSlice("scheduler-offsets"))
+		if err != nil {
+			return config, err
+		}
+		config.Scheduler.Offsets = offsets
+	}
+
+	return config, config.Validate()
+}
+
+// parseOffsets parses the event type offsets of the scheduler-offsets flag, given as eventTypeID=offset
+func parseOffsets(values []string) (map[string]time.Duration, error) {
+	offsets := make(map[string]time.Duration, len(values))
+	for _, value := range values {
+		eventTypeID, offset, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("scheduler-offsets: %q is not an eventTypeID=offset pair", value)
+		}
+		duration, err := time.ParseDuration(offset)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler-offsets: %v: %w", eventTypeID, err)
+		}
+		offsets[eventTypeID] = duration
+	}
+	return offsets, nil
+}
+
+// readFile overrides the configuration with the values of a YAML file, unknown keys are rejected. Relative paths in
+// the file are resolved against its directory.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config %v: %w", path, err)
+	}
+
+	// files named by the config file are relative to it, as the JWKS file of the auth file is
+	for _, file := range []*string{
+		&c.SportTypes, &c.Rules, &c.RemoteTransforms, &c.Auth, &c.Ladder.File,
+		&c.TLS.CertFile, &c.TLS.KeyFile, &c.TLS.ClientCAFile,
+		&c.MergerTLS.CAFile, &c.MergerTLS.CertFile, &c.MergerTLS.KeyFile,
+	} {
+		if *file != "" && !filepath.IsAbs(*file) {
+			*file = filepath.Join(filepath.Dir(path), *file)
+		}
+	}
+	return nil
+}
+
+// Validate checks the configuration, reporting every invalid value
+func (c Config) Validate() error {
+	var errs []error
+	if c.Repository.Backend != BackendRedis {
+		errs = append(errs, fmt.Errorf("repository.backend: unsupported backend %q, expected %q",
+			c.Repository.Backend, BackendRedis))
+	}
+	if c.Repository.Address == "" {
+		errs = append(errs, errors.New("repository.address: must be set"))
+	}
+	if _, err := repository.ParseCompression(c.Repository.Compression); err != nil {
+		errs = append(errs, fmt.Errorf("repository.compression: %w", err))
+	}
+	if c.GRPCPort <= 0 || c.GRPCPort > 65535 {
+		errs = append(errs, fmt.Errorf("grpcPort: %d is not a valid port", c.GRPCPort))
+	}
+	if c.HTTPPort <= 0 || c.HTTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("httpPort: %d is not a valid port", c.HTTPPort))
+	}
+	if c.GRPCPort == c.HTTPPort {
+		errs = append(errs, fmt.Errorf("grpcPort and httpPort: both are %d", c.GRPCPort))
+	}
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	if !slices.Contains([]string{LogFormatText, LogFormatJSON}, c.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format: unknown format %q, expected %q or %q",
+			c.Log.Format, LogFormatText, LogFormatJSON))
+	}
+	if !slices.Contains([]string{StaleUpdatesDrop, StaleUpdatesPartial}, c.StaleUpdates) {
+		errs = append(errs, fmt.Errorf("staleUpdates: unknown policy %q, expected %q or %q",
+			c.StaleUpdates, StaleUpdatesDrop, StaleUpdatesPartial))
+	}
+	exporters := []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP}
+	if !slices.Contains(exporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter: unknown exporter %q, expected one of %v",
+			c.Tracing.Exporter, exporters))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sampleRatio: %v is not between 0 and 1", c.Tracing.SampleRatio))
+	}
+	if c.TLS.Enabled() && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls: certFile and keyFile must be set together"))
+	}
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		errs = append(errs, errors.New("tls.clientCAFile: needs certFile and keyFile"))
+	}
+	if (c.MergerTLS.CertFile == "") != (c.MergerTLS.KeyFile == "") {
+		errs = append(errs, errors.New("mergerTLS: certFile and keyFile must be set together"))
+	}
+	for _, method := range slices.Sorted(maps.Keys(c.RateLimits.Methods)) {
+		limit := c.RateLimits.Methods[method]
+		if !strings.HasPrefix(method, "/") || limit.Rate <= 0 || limit.Burst < 1 {
+			errs = append(errs, fmt.Errorf("rateLimits.methods: %v needs a full method name, a positive rate and a "+
+				"burst of at least 1", method))
+		}
+	}
+	if c.RateLimits.MaxConcurrent < 0 || c.RateLimits.LatencyTarget < 0 {
+		errs = append(errs, errors.New("rateLimits: maxConcurrent and latencyTarget must not be negative"))
+	}
+	if c.Ladder.MinPrice < 0 {
+		errs = append(errs, errors.New("ladder.minPrice: must not be negative"))
+	}
+	if !slices.Contains([]string{BelowMinimumFlag, BelowMinimumReject}, c.Ladder.BelowMinimum) {
+		errs = append(errs, fmt.Errorf("ladder.belowMinimum: unknown policy %q, expected %q or %q",
+			c.Ladder.BelowMinimum, BelowMinimumFlag, BelowMinimumReject))
+	}
+	if c.Overround.MinMargin <= -1 || c.Overround.MaxMargin < 0 {
+		errs = append(errs, errors.New("overround: minMargin must be above -1 and maxMargin must not be negative"))
+	}
+	if c.Overround.MaxMargin != 0 && c.Overround.MaxMargin <= c.Overround.MinMargin {
+		errs = append(errs, fmt.Errorf("overround.maxMargin: %v is not above minMargin %v", c.Overround.MaxMargin,
+			c.Overround.MinMargin))
+	}
+	for eventTypeID := range c.Scheduler.Offsets {
+		if eventTypeID == "" {
+			errs = append(errs, errors.New("scheduler.offsets: event type must be set"))
+		}
+	}
+	if c.Timeouts.Startup <= 0 {
+		errs = append(errs, errors.New("timeouts.startup: must be positive"))
+	}
+	if c.Timeouts.Update < 0 {
+		errs = append(errs, errors.New("timeouts.update: must not be negative"))
+	}
+	if c.Timeouts.Shutdown <= 0 {
+		errs = append(errs, errors.New("timeouts.shutdown: must be positive"))
+	}
+	if c.Timeouts.Merge <= 0 {
+		errs = append(errs, errors.New("timeouts.merge: must be positive"))
+	}
+	if c.Timeouts.Drain < 0 || c.Timeouts.Drain >= c.Timeouts.Shutdown {
+		errs = append(errs, errors.New("timeouts.drain: must not be negative and must be shorter than timeouts.shutdown"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ConfigureLogging applies the log level and format to the standard logger
+func (c Config) ConfigureLogging() {
+	level, err := logrus.ParseLevel(c.Log.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	logrus.SetLevel(level)
+
+	if c.Log.Format == LogFormatJSON {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	}
+}
+
+// TransformEnabled reports whether a built-in transform is enabled
+func (c Config) TransformEnabled(name string) bool {
+	return len(c.Transforms) == 0 || slices.Contains(c.Transforms, name)
+}
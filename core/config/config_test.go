@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/config"
+)
+
+// load runs a cli app with the config flags and returns the configuration it loads
+func load(t *testing.T, args ...string) (config.Config, error) {
+	t.Helper()
+
+	var cfg config.Config
+	var errLoad error
+	app := cli.NewApp()
+	app.Flags = config.Flags()
+	app.Action = func(c *cli.Context) error {
+		cfg, errLoad = config.Load(c)
+		return nil
+	}
+	if err := app.Run(append([]string{"core"}, args...)); err != nil {
+		t.Fatalf("failed to run app: %v", err)
+	}
+	return cfg, errLoad
+}
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Repository.Address != "localhost:6379" || cfg.GRPCPort != 50051 || cfg.HTTPPort != 8080 {
+		t.Fatalf("unexpected defaults %+v", cfg)
+	}
+	if !cfg.TransformEnabled("LadderTransform") {
+		t.Fatalf("expected every transform to be enabled by default")
+	}
+	if !cfg.StatusRollup.CascadeClose || !cfg.StatusRollup.CascadeSuspend || !cfg.StatusRollup.CloseWhenAllMarketsClosed {
+		t.Fatalf("expected every hierarchy rule to be enforced by default, got %+v", cfg.StatusRollup)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `repository:
+  address: redis:6379
+grpcPort: 6000
+log:
+  level: debug
+transforms: [SportsTransform]
+rules: /etc/core/rules.yaml
+timeouts:
+  update: 2s
+rateLimits:
+  methods:
+    /core.Service/Update: {rate: 50, burst: 100}
+  latencyTarget: 20ms
+scheduler:
+  offsets:
+    horse_racing: -1m
+ladder:
+  file: ladder.yaml
+mergerTLS:
+  caFile: merger-ca.pem
+overround:
+  minMargin: 0.02
+  maxMargin: 0.3
+statusRollup:
+  cascadeSuspend: false
+`)
+	t.Setenv("CORE_GRPC_PORT", "7000")
+
+	cfg, err := load(t, "--config", path, "--log-format", "json", "--max-concurrent-requests", "32", "--auth", "auth.yaml",
+		"--scheduler-default-offset", "30s", "--overround-auto-suspend", "--reapply-sports",
+		"--status-rollup-close-event=false")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Repository.Address != "redis:6379" || cfg.Log.Level != "debug" || cfg.Timeouts.Update != 2*time.Second {
+		t.Fatalf("expected values from the file, got %+v", cfg)
+	}
+	if cfg.GRPCPort != 7000 || cfg.Log.Format != config.LogFormatJSON {
+		t.Fatalf("expected flags and environment to override the file, got %+v", cfg)
+	}
+	if cfg.HTTPPort != 8080 || cfg.Timeouts.Shutdown != 10*time.Second || cfg.Timeouts.Merge != 500*time.Millisecond {
+		t.Fatalf("expected defaults for unset values, got %+v", cfg)
+	}
+	limits := cfg.RateLimits
+	if limits.Methods["/core.Service/Update"] != (config.RateLimit{Rate: 50, Burst: 100}) ||
+		limits.LatencyTarget != 20*time.Millisecond || limits.MaxConcurrent != 32 {
+		t.Fatalf("unexpected rate limits %+v", limits)
+	}
+	if cfg.Scheduler.Offsets["horse_racing"] != -time.Minute || cfg.Scheduler.DefaultOffset != 30*time.Second {
+		t.Fatalf("unexpected scheduler offsets %+v", cfg.Scheduler)
+	}
+	if !cfg.ReapplySports {
+		t.Fatalf("expected sport names to be re-applied")
+	}
+	ladderFile := filepath.Join(filepath.Dir(path), "ladder.yaml")
+	if cfg.Ladder != (config.Ladder{File: ladderFile, BelowMinimum: config.BelowMinimumFlag}) {
+		t.Fatalf("unexpected ladder %+v", cfg.Ladder)
+	}
+	if cfg.Overround != (config.Overround{MinMargin: 0.02, MaxMargin: 0.3, AutoSuspend: true}) {
+		t.Fatalf("unexpected overround %+v", cfg.Overround)
+	}
+	if cfg.MergerTLS.CAFile != filepath.Join(filepath.Dir(path), "merger-ca.pem") {
+		t.Fatalf("unexpected merger tls %+v", cfg.MergerTLS)
+	}
+	if cfg.Rules != "/etc/core/rules.yaml" || cfg.Auth != "auth.yaml" {
+		t.Fatalf("expected absolute paths and flags to be kept as they are, got %v and %v", cfg.Rules, cfg.Auth)
+	}
+	if cfg.StatusRollup != (config.StatusRollup{CascadeClose: true}) {
+		t.Fatalf("unexpected status rollup %+v", cfg.StatusRollup)
+	}
+	if !cfg.TransformEnabled("SportsTransform") || cfg.TransformEnabled("LadderTransform") {
+		t.Fatalf("expected only the SportsTransform to be enabled, got %v", cfg.Transforms)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := load(t, "--repository-backend", "memcached", "--http-port", "50051", "--log-level", "loud",
+		"--tls-key-file", "server.key", "--repository-compression", "gzip", "--overround-min-margin", "0.1",
+		"--overround-max-margin", "0.05", "--ladder-below-minimum", "drop", "--merger-tls-cert-file", "client.crt")
+	if err == nil {
+		t.Fatalf("expected an invalid configuration")
+	}
+	for _, want := range []string{
+		"repository.backend", "repository.compression", "grpcPort and httpPort", "log.level", "tls",
+		"overround.maxMargin", "ladder.belowMinimum", "mergerTLS",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to report %v, got %v", want, err)
+		}
+	}
+
+	if _, err := load(t, "--config", writeFile(t, "grpcport: 1\n")); err == nil {
+		t.Fatalf("expected an error for an unknown key")
+	}
+	if _, err := load(t, "--scheduler-offsets", "horse_racing"); err == nil {
+		t.Fatalf("expected an error for an offset without a duration")
+	}
+}
@@ -23,9 +23,54 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type OddsFormat int32
+
+const (
+	OddsFormat_OddsDecimal    OddsFormat = 0
+	OddsFormat_OddsFractional OddsFormat = 1
+	OddsFormat_OddsAmerican   OddsFormat = 2
+)
+
+// Enum value maps for OddsFormat.
+var (
+	OddsFormat_name = map[int32]string{
+		0: "OddsDecimal",
+		1: "OddsFractional",
+		2: "OddsAmerican",
+	}
+	OddsFormat_value = map[string]int32{
+		"OddsDecimal":    0,
+		"OddsFractional": 1,
+		"OddsAmerican":   2,
+	}
+)
+
+func (x OddsFormat) Enum() *OddsFormat {
+	p := new(OddsFormat)
+	*p = x
+	return p
+}
+
+func (x OddsFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OddsFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_core_proto_enumTypes[0].Descriptor()
+}
+
+func (OddsFormat) Type() protoreflect.EnumType {
+	return &file_core_proto_enumTypes[0]
+}
+
+func (x OddsFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
 type UpdateRequest struct {
 	state         protoimpl.MessageState `protogen:"hybrid.v1"`
 	Event         *model.Event           `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Sequence      int64                  `protobuf:"varint,2,opt,name=Sequence,proto3" json:"Sequence,omitempty"` // source timestamp or sequence number, updates older than the last applied one are stale
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -62,10 +107,21 @@ func (x *UpdateRequest) GetEvent() *model.Event {
 	return nil
 }
 
+func (x *UpdateRequest) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 func (x *UpdateRequest) SetEvent(v *model.Event) {
 	x.Event = v
 }
 
+func (x *UpdateRequest) SetSequence(v int64) {
+	x.Sequence = v
+}
+
 func (x *UpdateRequest) HasEvent() bool {
 	if x == nil {
 		return false
@@ -80,7 +136,8 @@ func (x *UpdateRequest) ClearEvent() {
 type UpdateRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Event *model.Event
+	Event    *model.Event
+	Sequence int64
 }
 
 func (b0 UpdateRequest_builder) Build() *UpdateRequest {
@@ -88,14 +145,17 @@ func (b0 UpdateRequest_builder) Build() *UpdateRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.Event = b.Event
+	x.Sequence = b.Sequence
 	return m0
 }
 
 type UpdateResponse struct {
-	state         protoimpl.MessageState `protogen:"hybrid.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"hybrid.v1"`
+	Message           string                 `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+	Stale             bool                   `protobuf:"varint,2,opt,name=Stale,proto3" json:"Stale,omitempty"`                        // the update was older than the last applied one and was dropped or only partially applied
+	TransformFailures []*TransformFailure    `protobuf:"bytes,3,rep,name=TransformFailures,proto3" json:"TransformFailures,omitempty"` // transforms that failed and were skipped
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *UpdateResponse) Reset() {
@@ -130,14 +190,38 @@ func (x *UpdateResponse) GetMessage() string {
 	return ""
 }
 
+func (x *UpdateResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *UpdateResponse) GetTransformFailures() []*TransformFailure {
+	if x != nil {
+		return x.TransformFailures
+	}
+	return nil
+}
+
 func (x *UpdateResponse) SetMessage(v string) {
 	x.Message = v
 }
 
+func (x *UpdateResponse) SetStale(v bool) {
+	x.Stale = v
+}
+
+func (x *UpdateResponse) SetTransformFailures(v []*TransformFailure) {
+	x.TransformFailures = v
+}
+
 type UpdateResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Message string
+	Message           string
+	Stale             bool
+	TransformFailures []*TransformFailure
 }
 
 func (b0 UpdateResponse_builder) Build() *UpdateResponse {
@@ -145,19 +229,93 @@ func (b0 UpdateResponse_builder) Build() *UpdateResponse {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.Message = b.Message
+	x.Stale = b.Stale
+	x.TransformFailures = b.TransformFailures
+	return m0
+}
+
+type TransformFailure struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	Transform     string                 `protobuf:"bytes,1,opt,name=Transform,proto3" json:"Transform,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformFailure) Reset() {
+	*x = TransformFailure{}
+	mi := &file_core_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformFailure) ProtoMessage() {}
+
+func (x *TransformFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TransformFailure) GetTransform() string {
+	if x != nil {
+		return x.Transform
+	}
+	return ""
+}
+
+func (x *TransformFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TransformFailure) SetTransform(v string) {
+	x.Transform = v
+}
+
+func (x *TransformFailure) SetError(v string) {
+	x.Error = v
+}
+
+type TransformFailure_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Transform string
+	Error     string
+}
+
+func (b0 TransformFailure_builder) Build() *TransformFailure {
+	m0 := &TransformFailure{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.Transform = b.Transform
+	x.Error = b.Error
 	return m0
 }
 
 type GetSportEventRequest struct {
 	state         protoimpl.MessageState `protogen:"hybrid.v1"`
 	EventID       string                 `protobuf:"bytes,1,opt,name=EventID,proto3" json:"EventID,omitempty"`
+	OddsFormat    OddsFormat             `protobuf:"varint,2,opt,name=OddsFormat,proto3,enum=core.OddsFormat" json:"OddsFormat,omitempty"` // format used for SelectionPrice.Display
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetSportEventRequest) Reset() {
 	*x = GetSportEventRequest{}
-	mi := &file_core_proto_msgTypes[2]
+	mi := &file_core_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -169,7 +327,7 @@ func (x *GetSportEventRequest) String() string {
 func (*GetSportEventRequest) ProtoMessage() {}
 
 func (x *GetSportEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_core_proto_msgTypes[2]
+	mi := &file_core_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -187,14 +345,26 @@ func (x *GetSportEventRequest) GetEventID() string {
 	return ""
 }
 
+func (x *GetSportEventRequest) GetOddsFormat() OddsFormat {
+	if x != nil {
+		return x.OddsFormat
+	}
+	return OddsFormat_OddsDecimal
+}
+
 func (x *GetSportEventRequest) SetEventID(v string) {
 	x.EventID = v
 }
 
+func (x *GetSportEventRequest) SetOddsFormat(v OddsFormat) {
+	x.OddsFormat = v
+}
+
 type GetSportEventRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	EventID string
+	EventID    string
+	OddsFormat OddsFormat
 }
 
 func (b0 GetSportEventRequest_builder) Build() *GetSportEventRequest {
@@ -202,6 +372,7 @@ func (b0 GetSportEventRequest_builder) Build() *GetSportEventRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.EventID = b.EventID
+	x.OddsFormat = b.OddsFormat
 	return m0
 }
 
@@ -214,7 +385,7 @@ type GetSportEventResponse struct {
 
 func (x *GetSportEventResponse) Reset() {
 	*x = GetSportEventResponse{}
-	mi := &file_core_proto_msgTypes[3]
+	mi := &file_core_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -226,7 +397,7 @@ func (x *GetSportEventResponse) String() string {
 func (*GetSportEventResponse) ProtoMessage() {}
 
 func (x *GetSportEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_core_proto_msgTypes[3]
+	mi := &file_core_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -285,13 +456,14 @@ type SportEvent struct {
 	Region        string                 `protobuf:"bytes,8,opt,name=Region,proto3" json:"Region,omitempty"`
 	League        string                 `protobuf:"bytes,9,opt,name=League,proto3" json:"League,omitempty"`
 	Round         string                 `protobuf:"bytes,11,opt,name=Round,proto3" json:"Round,omitempty"`
+	Prices        []*SelectionPrice      `protobuf:"bytes,12,rep,name=Prices,proto3" json:"Prices,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SportEvent) Reset() {
 	*x = SportEvent{}
-	mi := &file_core_proto_msgTypes[4]
+	mi := &file_core_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -303,7 +475,7 @@ func (x *SportEvent) String() string {
 func (*SportEvent) ProtoMessage() {}
 
 func (x *SportEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_core_proto_msgTypes[4]
+	mi := &file_core_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -384,6 +556,13 @@ func (x *SportEvent) GetRound() string {
 	return ""
 }
 
+func (x *SportEvent) GetPrices() []*SelectionPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
 func (x *SportEvent) SetID(v string) {
 	x.ID = v
 }
@@ -424,6 +603,10 @@ func (x *SportEvent) SetRound(v string) {
 	x.Round = v
 }
 
+func (x *SportEvent) SetPrices(v []*SelectionPrice) {
+	x.Prices = v
+}
+
 type SportEvent_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -437,6 +620,7 @@ type SportEvent_builder struct {
 	Region        string
 	League        string
 	Round         string
+	Prices        []*SelectionPrice
 }
 
 func (b0 SportEvent_builder) Build() *SportEvent {
@@ -453,6 +637,541 @@ func (b0 SportEvent_builder) Build() *SportEvent {
 	x.Region = b.Region
 	x.League = b.League
 	x.Round = b.Round
+	x.Prices = b.Prices
+	return m0
+}
+
+// SelectionPrice is the price of a selection in every supported odds format
+type SelectionPrice struct {
+	state              protoimpl.MessageState `protogen:"hybrid.v1"`
+	MarketID           string                 `protobuf:"bytes,1,opt,name=MarketID,proto3" json:"MarketID,omitempty"`
+	SelectionID        string                 `protobuf:"bytes,2,opt,name=SelectionID,proto3" json:"SelectionID,omitempty"`
+	Decimal            float64                `protobuf:"fixed64,3,opt,name=Decimal,proto3" json:"Decimal,omitempty"`
+	Fractional         string                 `protobuf:"bytes,4,opt,name=Fractional,proto3" json:"Fractional,omitempty"` // nearest price on the standard fractional ladder
+	American           string                 `protobuf:"bytes,5,opt,name=American,proto3" json:"American,omitempty"`
+	ImpliedProbability float64                `protobuf:"fixed64,6,opt,name=ImpliedProbability,proto3" json:"ImpliedProbability,omitempty"`
+	Display            string                 `protobuf:"bytes,7,opt,name=Display,proto3" json:"Display,omitempty"` // the price in the format requested
+	OpeningPrice       float64                `protobuf:"fixed64,8,opt,name=OpeningPrice,proto3" json:"OpeningPrice,omitempty"`
+	PreviousPrice      float64                `protobuf:"fixed64,9,opt,name=PreviousPrice,proto3" json:"PreviousPrice,omitempty"`
+	Movement           string                 `protobuf:"bytes,10,opt,name=Movement,proto3" json:"Movement,omitempty"` // Firming when the price shortened since the previous price, Drifting when it lengthened
+	Changes            []*model.PriceChange   `protobuf:"bytes,11,rep,name=Changes,proto3" json:"Changes,omitempty"`   // the most recent changes, oldest first, at most MaxPriceChanges
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SelectionPrice) Reset() {
+	*x = SelectionPrice{}
+	mi := &file_core_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelectionPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectionPrice) ProtoMessage() {}
+
+func (x *SelectionPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SelectionPrice) GetMarketID() string {
+	if x != nil {
+		return x.MarketID
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetSelectionID() string {
+	if x != nil {
+		return x.SelectionID
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetDecimal() float64 {
+	if x != nil {
+		return x.Decimal
+	}
+	return 0
+}
+
+func (x *SelectionPrice) GetFractional() string {
+	if x != nil {
+		return x.Fractional
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetAmerican() string {
+	if x != nil {
+		return x.American
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetImpliedProbability() float64 {
+	if x != nil {
+		return x.ImpliedProbability
+	}
+	return 0
+}
+
+func (x *SelectionPrice) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetOpeningPrice() float64 {
+	if x != nil {
+		return x.OpeningPrice
+	}
+	return 0
+}
+
+func (x *SelectionPrice) GetPreviousPrice() float64 {
+	if x != nil {
+		return x.PreviousPrice
+	}
+	return 0
+}
+
+func (x *SelectionPrice) GetMovement() string {
+	if x != nil {
+		return x.Movement
+	}
+	return ""
+}
+
+func (x *SelectionPrice) GetChanges() []*model.PriceChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *SelectionPrice) SetMarketID(v string) {
+	x.MarketID = v
+}
+
+func (x *SelectionPrice) SetSelectionID(v string) {
+	x.SelectionID = v
+}
+
+func (x *SelectionPrice) SetDecimal(v float64) {
+	x.Decimal = v
+}
+
+func (x *SelectionPrice) SetFractional(v string) {
+	x.Fractional = v
+}
+
+func (x *SelectionPrice) SetAmerican(v string) {
+	x.American = v
+}
+
+func (x *SelectionPrice) SetImpliedProbability(v float64) {
+	x.ImpliedProbability = v
+}
+
+func (x *SelectionPrice) SetDisplay(v string) {
+	x.Display = v
+}
+
+func (x *SelectionPrice) SetOpeningPrice(v float64) {
+	x.OpeningPrice = v
+}
+
+func (x *SelectionPrice) SetPreviousPrice(v float64) {
+	x.PreviousPrice = v
+}
+
+func (x *SelectionPrice) SetMovement(v string) {
+	x.Movement = v
+}
+
+func (x *SelectionPrice) SetChanges(v []*model.PriceChange) {
+	x.Changes = v
+}
+
+type SelectionPrice_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	MarketID           string
+	SelectionID        string
+	Decimal            float64
+	Fractional         string
+	American           string
+	ImpliedProbability float64
+	Display            string
+	OpeningPrice       float64
+	PreviousPrice      float64
+	Movement           string
+	Changes            []*model.PriceChange
+}
+
+func (b0 SelectionPrice_builder) Build() *SelectionPrice {
+	m0 := &SelectionPrice{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.MarketID = b.MarketID
+	x.SelectionID = b.SelectionID
+	x.Decimal = b.Decimal
+	x.Fractional = b.Fractional
+	x.American = b.American
+	x.ImpliedProbability = b.ImpliedProbability
+	x.Display = b.Display
+	x.OpeningPrice = b.OpeningPrice
+	x.PreviousPrice = b.PreviousPrice
+	x.Movement = b.Movement
+	x.Changes = b.Changes
+	return m0
+}
+
+// SportType maps an EventTypeID to a sport and the default values of its events
+type SportType struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	EventTypeID   string                 `protobuf:"bytes,1,opt,name=EventTypeID,proto3" json:"EventTypeID,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	Region        string                 `protobuf:"bytes,3,opt,name=Region,proto3" json:"Region,omitempty"`
+	League        string                 `protobuf:"bytes,4,opt,name=League,proto3" json:"League,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SportType) Reset() {
+	*x = SportType{}
+	mi := &file_core_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SportType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SportType) ProtoMessage() {}
+
+func (x *SportType) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SportType) GetEventTypeID() string {
+	if x != nil {
+		return x.EventTypeID
+	}
+	return ""
+}
+
+func (x *SportType) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SportType) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *SportType) GetLeague() string {
+	if x != nil {
+		return x.League
+	}
+	return ""
+}
+
+func (x *SportType) SetEventTypeID(v string) {
+	x.EventTypeID = v
+}
+
+func (x *SportType) SetName(v string) {
+	x.Name = v
+}
+
+func (x *SportType) SetRegion(v string) {
+	x.Region = v
+}
+
+func (x *SportType) SetLeague(v string) {
+	x.League = v
+}
+
+type SportType_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	EventTypeID string
+	Name        string
+	Region      string
+	League      string
+}
+
+func (b0 SportType_builder) Build() *SportType {
+	m0 := &SportType{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.EventTypeID = b.EventTypeID
+	x.Name = b.Name
+	x.Region = b.Region
+	x.League = b.League
+	return m0
+}
+
+type ListSportTypesRequest struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSportTypesRequest) Reset() {
+	*x = ListSportTypesRequest{}
+	mi := &file_core_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSportTypesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSportTypesRequest) ProtoMessage() {}
+
+func (x *ListSportTypesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListSportTypesRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListSportTypesRequest_builder) Build() *ListSportTypesRequest {
+	m0 := &ListSportTypesRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListSportTypesResponse struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	SportTypes    []*SportType           `protobuf:"bytes,1,rep,name=SportTypes,proto3" json:"SportTypes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSportTypesResponse) Reset() {
+	*x = ListSportTypesResponse{}
+	mi := &file_core_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSportTypesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSportTypesResponse) ProtoMessage() {}
+
+func (x *ListSportTypesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListSportTypesResponse) GetSportTypes() []*SportType {
+	if x != nil {
+		return x.SportTypes
+	}
+	return nil
+}
+
+func (x *ListSportTypesResponse) SetSportTypes(v []*SportType) {
+	x.SportTypes = v
+}
+
+type ListSportTypesResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	SportTypes []*SportType
+}
+
+func (b0 ListSportTypesResponse_builder) Build() *ListSportTypesResponse {
+	m0 := &ListSportTypesResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.SportTypes = b.SportTypes
+	return m0
+}
+
+type UpdateSportTypeRequest struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	SportType     *SportType             `protobuf:"bytes,1,opt,name=SportType,proto3" json:"SportType,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSportTypeRequest) Reset() {
+	*x = UpdateSportTypeRequest{}
+	mi := &file_core_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSportTypeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSportTypeRequest) ProtoMessage() {}
+
+func (x *UpdateSportTypeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UpdateSportTypeRequest) GetSportType() *SportType {
+	if x != nil {
+		return x.SportType
+	}
+	return nil
+}
+
+func (x *UpdateSportTypeRequest) SetSportType(v *SportType) {
+	x.SportType = v
+}
+
+func (x *UpdateSportTypeRequest) HasSportType() bool {
+	if x == nil {
+		return false
+	}
+	return x.SportType != nil
+}
+
+func (x *UpdateSportTypeRequest) ClearSportType() {
+	x.SportType = nil
+}
+
+type UpdateSportTypeRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	SportType *SportType
+}
+
+func (b0 UpdateSportTypeRequest_builder) Build() *UpdateSportTypeRequest {
+	m0 := &UpdateSportTypeRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.SportType = b.SportType
+	return m0
+}
+
+type UpdateSportTypeResponse struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	SportType     *SportType             `protobuf:"bytes,1,opt,name=SportType,proto3" json:"SportType,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSportTypeResponse) Reset() {
+	*x = UpdateSportTypeResponse{}
+	mi := &file_core_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSportTypeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSportTypeResponse) ProtoMessage() {}
+
+func (x *UpdateSportTypeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_core_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UpdateSportTypeResponse) GetSportType() *SportType {
+	if x != nil {
+		return x.SportType
+	}
+	return nil
+}
+
+func (x *UpdateSportTypeResponse) SetSportType(v *SportType) {
+	x.SportType = v
+}
+
+func (x *UpdateSportTypeResponse) HasSportType() bool {
+	if x == nil {
+		return false
+	}
+	return x.SportType != nil
+}
+
+func (x *UpdateSportTypeResponse) ClearSportType() {
+	x.SportType = nil
+}
+
+type UpdateSportTypeResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	SportType *SportType
+}
+
+func (b0 UpdateSportTypeResponse_builder) Build() *UpdateSportTypeResponse {
+	m0 := &UpdateSportTypeResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.SportType = b.SportType
 	return m0
 }
 
@@ -461,15 +1180,24 @@ var File_core_proto protoreflect.FileDescriptor
 const file_core_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"core.proto\x12\x04core\x1a\x11model/event.proto\"3\n" +
+	"core.proto\x12\x04core\x1a\x11model/event.proto\"O\n" +
 	"\rUpdateRequest\x12\"\n" +
-	"\x05Event\x18\x01 \x01(\v2\f.model.EventR\x05Event\"*\n" +
+	"\x05Event\x18\x01 \x01(\v2\f.model.EventR\x05Event\x12\x1a\n" +
+	"\bSequence\x18\x02 \x01(\x03R\bSequence\"\x86\x01\n" +
 	"\x0eUpdateResponse\x12\x18\n" +
-	"\aMessage\x18\x01 \x01(\tR\aMessage\"0\n" +
+	"\aMessage\x18\x01 \x01(\tR\aMessage\x12\x14\n" +
+	"\x05Stale\x18\x02 \x01(\bR\x05Stale\x12D\n" +
+	"\x11TransformFailures\x18\x03 \x03(\v2\x16.core.TransformFailureR\x11TransformFailures\"F\n" +
+	"\x10TransformFailure\x12\x1c\n" +
+	"\tTransform\x18\x01 \x01(\tR\tTransform\x12\x14\n" +
+	"\x05Error\x18\x02 \x01(\tR\x05Error\"b\n" +
 	"\x14GetSportEventRequest\x12\x18\n" +
-	"\aEventID\x18\x01 \x01(\tR\aEventID\"?\n" +
+	"\aEventID\x18\x01 \x01(\tR\aEventID\x120\n" +
+	"\n" +
+	"OddsFormat\x18\x02 \x01(\x0e2\x10.core.OddsFormatR\n" +
+	"OddsFormat\"?\n" +
 	"\x15GetSportEventResponse\x12&\n" +
-	"\x05Event\x18\x01 \x01(\v2\x10.core.SportEventR\x05Event\"\xa3\x02\n" +
+	"\x05Event\x18\x01 \x01(\v2\x10.core.SportEventR\x05Event\"\xd1\x02\n" +
 	"\n" +
 	"SportEvent\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x12\n" +
@@ -481,34 +1209,92 @@ const file_core_proto_rawDesc = "" +
 	"\tSportName\x18\a \x01(\tR\tSportName\x12\x16\n" +
 	"\x06Region\x18\b \x01(\tR\x06Region\x12\x16\n" +
 	"\x06League\x18\t \x01(\tR\x06League\x12\x14\n" +
-	"\x05Round\x18\v \x01(\tR\x05Round2\x8c\x01\n" +
+	"\x05Round\x18\v \x01(\tR\x05Round\x12,\n" +
+	"\x06Prices\x18\f \x03(\v2\x14.core.SelectionPriceR\x06Prices\"\x82\x03\n" +
+	"\x0eSelectionPrice\x12\x1a\n" +
+	"\bMarketID\x18\x01 \x01(\tR\bMarketID\x12 \n" +
+	"\vSelectionID\x18\x02 \x01(\tR\vSelectionID\x12\x18\n" +
+	"\aDecimal\x18\x03 \x01(\x01R\aDecimal\x12\x1e\n" +
+	"\n" +
+	"Fractional\x18\x04 \x01(\tR\n" +
+	"Fractional\x12\x1a\n" +
+	"\bAmerican\x18\x05 \x01(\tR\bAmerican\x12.\n" +
+	"\x12ImpliedProbability\x18\x06 \x01(\x01R\x12ImpliedProbability\x12\x18\n" +
+	"\aDisplay\x18\a \x01(\tR\aDisplay\x12\"\n" +
+	"\fOpeningPrice\x18\b \x01(\x01R\fOpeningPrice\x12$\n" +
+	"\rPreviousPrice\x18\t \x01(\x01R\rPreviousPrice\x12\x1a\n" +
+	"\bMovement\x18\n" +
+	" \x01(\tR\bMovement\x12,\n" +
+	"\aChanges\x18\v \x03(\v2\x12.model.PriceChangeR\aChanges\"q\n" +
+	"\tSportType\x12 \n" +
+	"\vEventTypeID\x18\x01 \x01(\tR\vEventTypeID\x12\x12\n" +
+	"\x04Name\x18\x02 \x01(\tR\x04Name\x12\x16\n" +
+	"\x06Region\x18\x03 \x01(\tR\x06Region\x12\x16\n" +
+	"\x06League\x18\x04 \x01(\tR\x06League\"\x17\n" +
+	"\x15ListSportTypesRequest\"I\n" +
+	"\x16ListSportTypesResponse\x12/\n" +
+	"\n" +
+	"SportTypes\x18\x01 \x03(\v2\x0f.core.SportTypeR\n" +
+	"SportTypes\"G\n" +
+	"\x16UpdateSportTypeRequest\x12-\n" +
+	"\tSportType\x18\x01 \x01(\v2\x0f.core.SportTypeR\tSportType\"H\n" +
+	"\x17UpdateSportTypeResponse\x12-\n" +
+	"\tSportType\x18\x01 \x01(\v2\x0f.core.SportTypeR\tSportType*C\n" +
+	"\n" +
+	"OddsFormat\x12\x0f\n" +
+	"\vOddsDecimal\x10\x00\x12\x12\n" +
+	"\x0eOddsFractional\x10\x01\x12\x10\n" +
+	"\fOddsAmerican\x10\x022\xad\x02\n" +
 	"\aService\x125\n" +
 	"\x06Update\x12\x13.core.UpdateRequest\x1a\x14.core.UpdateResponse\"\x00\x12J\n" +
-	"\rGetSportEvent\x12\x1a.core.GetSportEventRequest\x1a\x1b.core.GetSportEventResponse\"\x00B:Z8git.neds.sh/technology/pricekinetics/tools/codetest/coreb\x06proto3"
+	"\rGetSportEvent\x12\x1a.core.GetSportEventRequest\x1a\x1b.core.GetSportEventResponse\"\x00\x12M\n" +
+	"\x0eListSportTypes\x12\x1b.core.ListSportTypesRequest\x1a\x1c.core.ListSportTypesResponse\"\x00\x12P\n" +
+	"\x0fUpdateSportType\x12\x1c.core.UpdateSportTypeRequest\x1a\x1d.core.UpdateSportTypeResponse\"\x00B:Z8git.neds.sh/technology/pricekinetics/tools/codetest/coreb\x06proto3"
 
-var file_core_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_core_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_core_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_core_proto_goTypes = []any{
-	(*UpdateRequest)(nil),         // 0: core.UpdateRequest
-	(*UpdateResponse)(nil),        // 1: core.UpdateResponse
-	(*GetSportEventRequest)(nil),  // 2: core.GetSportEventRequest
-	(*GetSportEventResponse)(nil), // 3: core.GetSportEventResponse
-	(*SportEvent)(nil),            // 4: core.SportEvent
-	(*model.Event)(nil),           // 5: model.Event
-	(*model.Market)(nil),          // 6: model.Market
+	(OddsFormat)(0),                 // 0: core.OddsFormat
+	(*UpdateRequest)(nil),           // 1: core.UpdateRequest
+	(*UpdateResponse)(nil),          // 2: core.UpdateResponse
+	(*TransformFailure)(nil),        // 3: core.TransformFailure
+	(*GetSportEventRequest)(nil),    // 4: core.GetSportEventRequest
+	(*GetSportEventResponse)(nil),   // 5: core.GetSportEventResponse
+	(*SportEvent)(nil),              // 6: core.SportEvent
+	(*SelectionPrice)(nil),          // 7: core.SelectionPrice
+	(*SportType)(nil),               // 8: core.SportType
+	(*ListSportTypesRequest)(nil),   // 9: core.ListSportTypesRequest
+	(*ListSportTypesResponse)(nil),  // 10: core.ListSportTypesResponse
+	(*UpdateSportTypeRequest)(nil),  // 11: core.UpdateSportTypeRequest
+	(*UpdateSportTypeResponse)(nil), // 12: core.UpdateSportTypeResponse
+	(*model.Event)(nil),             // 13: model.Event
+	(*model.Market)(nil),            // 14: model.Market
+	(*model.PriceChange)(nil),       // 15: model.PriceChange
 }
 var file_core_proto_depIdxs = []int32{
-	5, // 0: core.UpdateRequest.Event:type_name -> model.Event
-	4, // 1: core.GetSportEventResponse.Event:type_name -> core.SportEvent
-	6, // 2: core.SportEvent.Markets:type_name -> model.Market
-	0, // 3: core.Service.Update:input_type -> core.UpdateRequest
-	2, // 4: core.Service.GetSportEvent:input_type -> core.GetSportEventRequest
-	1, // 5: core.Service.Update:output_type -> core.UpdateResponse
-	3, // 6: core.Service.GetSportEvent:output_type -> core.GetSportEventResponse
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	13, // 0: core.UpdateRequest.Event:type_name -> model.Event
+	3,  // 1: core.UpdateResponse.TransformFailures:type_name -> core.TransformFailure
+	0,  // 2: core.GetSportEventRequest.OddsFormat:type_name -> core.OddsFormat
+	6,  // 3: core.GetSportEventResponse.Event:type_name -> core.SportEvent
+	14, // 4: core.SportEvent.Markets:type_name -> model.Market
+	7,  // 5: core.SportEvent.Prices:type_name -> core.SelectionPrice
+	15, // 6: core.SelectionPrice.Changes:type_name -> model.PriceChange
+	8,  // 7: core.ListSportTypesResponse.SportTypes:type_name -> core.SportType
+	8,  // 8: core.UpdateSportTypeRequest.SportType:type_name -> core.SportType
+	8,  // 9: core.UpdateSportTypeResponse.SportType:type_name -> core.SportType
+	1,  // 10: core.Service.Update:input_type -> core.UpdateRequest
+	4,  // 11: core.Service.GetSportEvent:input_type -> core.GetSportEventRequest
+	9,  // 12: core.Service.ListSportTypes:input_type -> core.ListSportTypesRequest
+	11, // 13: core.Service.UpdateSportType:input_type -> core.UpdateSportTypeRequest
+	2,  // 14: core.Service.Update:output_type -> core.UpdateResponse
+	5,  // 15: core.Service.GetSportEvent:output_type -> core.GetSportEventResponse
+	10, // 16: core.Service.ListSportTypes:output_type -> core.ListSportTypesResponse
+	12, // 17: core.Service.UpdateSportType:output_type -> core.UpdateSportTypeResponse
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_core_proto_init() }
@@ -521,13 +1307,14 @@ func file_core_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_core_proto_rawDesc), len(file_core_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_core_proto_goTypes,
 		DependencyIndexes: file_core_proto_depIdxs,
+		EnumInfos:         file_core_proto_enumTypes,
 		MessageInfos:      file_core_proto_msgTypes,
 	}.Build()
 	File_core_proto = out.File
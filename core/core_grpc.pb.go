@@ -19,8 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Service_Update_FullMethodName        = "/core.Service/Update"
-	Service_GetSportEvent_FullMethodName = "/core.Service/GetSportEvent"
+	Service_Update_FullMethodName          = "/core.Service/Update"
+	Service_GetSportEvent_FullMethodName   = "/core.Service/GetSportEvent"
+	Service_ListSportTypes_FullMethodName  = "/core.Service/ListSportTypes"
+	Service_UpdateSportType_FullMethodName = "/core.Service/UpdateSportType"
 )
 
 // ServiceClient is the client API for Service service.
@@ -31,6 +33,10 @@ type ServiceClient interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	// GetSportEvent retrieves a model.Event from the database and returns a core.SportEvent - this is a more UserConsumable representation of the model that is specific to sport events
 	GetSportEvent(ctx context.Context, in *GetSportEventRequest, opts ...grpc.CallOption) (*GetSportEventResponse, error)
+	// ListSportTypes lists the EventTypeID to sport mappings used by the sport transform
+	ListSportTypes(ctx context.Context, in *ListSportTypesRequest, opts ...grpc.CallOption) (*ListSportTypesResponse, error)
+	// UpdateSportType adds or replaces an EventTypeID to sport mapping, it is applied to events on their next update
+	UpdateSportType(ctx context.Context, in *UpdateSportTypeRequest, opts ...grpc.CallOption) (*UpdateSportTypeResponse, error)
 }
 
 type serviceClient struct {
@@ -61,6 +67,26 @@ func (c *serviceClient) GetSportEvent(ctx context.Context, in *GetSportEventRequ
 	return out, nil
 }
 
+func (c *serviceClient) ListSportTypes(ctx context.Context, in *ListSportTypesRequest, opts ...grpc.CallOption) (*ListSportTypesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSportTypesResponse)
+	err := c.cc.Invoke(ctx, Service_ListSportTypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) UpdateSportType(ctx context.Context, in *UpdateSportTypeRequest, opts ...grpc.CallOption) (*UpdateSportTypeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSportTypeResponse)
+	err := c.cc.Invoke(ctx, Service_UpdateSportType_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServiceServer is the server API for Service service.
 // All implementations should embed UnimplementedServiceServer
 // for forward compatibility.
@@ -69,6 +95,10 @@ type ServiceServer interface {
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	// GetSportEvent retrieves a model.Event from the database and returns a core.SportEvent - this is a more UserConsumable representation of the model that is specific to sport events
 	GetSportEvent(context.Context, *GetSportEventRequest) (*GetSportEventResponse, error)
+	// ListSportTypes lists the EventTypeID to sport mappings used by the sport transform
+	ListSportTypes(context.Context, *ListSportTypesRequest) (*ListSportTypesResponse, error)
+	// UpdateSportType adds or replaces an EventTypeID to sport mapping, it is applied to events on their next update
+	UpdateSportType(context.Context, *UpdateSportTypeRequest) (*UpdateSportTypeResponse, error)
 }
 
 // UnimplementedServiceServer should be embedded to have
@@ -84,6 +114,12 @@ func (UnimplementedServiceServer) Update(context.Context, *UpdateRequest) (*Upda
 func (UnimplementedServiceServer) GetSportEvent(context.Context, *GetSportEventRequest) (*GetSportEventResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSportEvent not implemented")
 }
+func (UnimplementedServiceServer) ListSportTypes(context.Context, *ListSportTypesRequest) (*ListSportTypesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSportTypes not implemented")
+}
+func (UnimplementedServiceServer) UpdateSportType(context.Context, *UpdateSportTypeRequest) (*UpdateSportTypeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateSportType not implemented")
+}
 func (UnimplementedServiceServer) testEmbeddedByValue() {}
 
 // UnsafeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -140,6 +176,42 @@ func _Service_GetSportEvent_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_ListSportTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSportTypesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ListSportTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ListSportTypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ListSportTypes(ctx, req.(*ListSportTypesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_UpdateSportType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSportTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).UpdateSportType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_UpdateSportType_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).UpdateSportType(ctx, req.(*UpdateSportTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Service_ServiceDesc is the grpc.ServiceDesc for Service service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -155,6 +227,14 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSportEvent",
 			Handler:    _Service_GetSportEvent_Handler,
 		},
+		{
+			MethodName: "ListSportTypes",
+			Handler:    _Service_ListSportTypes_Handler,
+		},
+		{
+			MethodName: "UpdateSportType",
+			Handler:    _Service_UpdateSportType_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "core.proto",
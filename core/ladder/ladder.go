@@ -0,0 +1,122 @@
+// Package ladder defines the price ladders that selection prices have to sit on
+package ladder
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Band is a range of a ladder in which prices move in fixed increments
+type Band struct {
+	From      float64 `yaml:"from"`
+	To        float64 `yaml:"to"`
+	Increment float64 `yaml:"increment"`
+}
+
+// Ladder is an ordered set of contiguous bands, covering every valid price from the first band to the last
+type Ladder struct {
+	Name  string `yaml:"name"`
+	Bands []Band `yaml:"bands"`
+}
+
+// Betfair returns the Betfair style ladder, from 1.01 to 1000
+func Betfair() *Ladder {
+	return &Ladder{
+		Name: "betfair",
+		Bands: []Band{
+			{From: 1.01, To: 2, Increment: 0.01},
+			{From: 2, To: 3, Increment: 0.02},
+			{From: 3, To: 4, Increment: 0.05},
+			{From: 4, To: 6, Increment: 0.1},
+			{From: 6, To: 10, Increment: 0.2},
+			{From: 10, To: 20, Increment: 0.5},
+			{From: 20, To: 30, Increment: 1},
+			{From: 30, To: 50, Increment: 2},
+			{From: 50, To: 100, Increment: 5},
+			{From: 100, To: 1000, Increment: 10},
+		},
+	}
+}
+
+// Load reads a ladder definition from a YAML file and validates it
+func Load(path string) (*Ladder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed_to_read_ladder: %w", err)
+	}
+
+	l := &Ladder{}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed_to_parse_ladder: %w", err)
+	}
+
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Validate checks that the bands of the ladder are ordered, contiguous and have positive increments
+func (l *Ladder) Validate() error {
+	if len(l.Bands) == 0 {
+		return fmt.Errorf("ladder %q has no bands", l.Name)
+	}
+
+	for i, band := range l.Bands {
+		if band.Increment <= 0 {
+			return fmt.Errorf("ladder %q band %d has a non positive increment", l.Name, i)
+		}
+		if band.From >= band.To {
+			return fmt.Errorf("ladder %q band %d does not start before it ends", l.Name, i)
+		}
+		if i > 0 && band.From != l.Bands[i-1].To {
+			return fmt.Errorf("ladder %q band %d does not start where band %d ends", l.Name, i, i-1)
+		}
+	}
+
+	return nil
+}
+
+// Min returns the lowest price on the ladder
+func (l *Ladder) Min() float64 {
+	return l.Bands[0].From
+}
+
+// Max returns the highest price on the ladder
+func (l *Ladder) Max() float64 {
+	return l.Bands[len(l.Bands)-1].To
+}
+
+// Snap returns the nearest price on the ladder, prices outside the ladder are moved to its closest end
+func (l *Ladder) Snap(price float64) float64 {
+	if price <= l.Min() {
+		return l.Min()
+	}
+	if price >= l.Max() {
+		return l.Max()
+	}
+
+	for _, band := range l.Bands {
+		if price >= band.To {
+			continue
+		}
+		ticks := math.Round((price - band.From) / band.Increment)
+		return round(band.From + ticks*band.Increment)
+	}
+
+	return l.Max()
+}
+
+// OnLadder reports whether the price is a valid price on the ladder
+func (l *Ladder) OnLadder(price float64) bool {
+	return l.Snap(price) == price
+}
+
+// round removes the floating point noise left by adding up increments
+func round(price float64) float64 {
+	return math.Round(price*10000) / 10000
+}
@@ -0,0 +1,63 @@
+package ladder_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ladder"
+)
+
+func TestSnap(t *testing.T) {
+	l := ladder.Betfair()
+	cases := map[float64]float64{
+		1.013:  1.01,
+		1.016:  1.02,
+		0.5:    1.01,
+		2.031:  2.04,
+		3.12:   3.1,
+		5.56:   5.6,
+		9.95:   10,
+		17.3:   17.5,
+		42.5:   42,
+		2000.0: 1000,
+		1.5:    1.5,
+	}
+
+	for price, want := range cases {
+		if got := l.Snap(price); got != want {
+			t.Fatalf("Snap(%v): expected %v, got %v", price, want, got)
+		}
+	}
+
+	if !l.OnLadder(2.02) || l.OnLadder(2.03) {
+		t.Fatalf("expected 2.02 to be on the ladder and 2.03 not to be")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ladder.yaml")
+	def := "name: coarse\nbands:\n  - {from: 1.1, to: 2, increment: 0.1}\n  - {from: 2, to: 10, increment: 1}\n"
+	if err := os.WriteFile(path, []byte(def), 0o600); err != nil {
+		t.Fatalf("failed to write ladder: %v", err)
+	}
+
+	l, err := ladder.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Name != "coarse" || l.Min() != 1.1 || l.Max() != 10 {
+		t.Fatalf("unexpected ladder %+v", l)
+	}
+	if got := l.Snap(4.4); got != 4 {
+		t.Fatalf("expected %v, got %v", 4, got)
+	}
+
+	gap := "name: gap\nbands:\n  - {from: 1.1, to: 2, increment: 0.1}\n  - {from: 3, to: 10, increment: 1}\n"
+	if err := os.WriteFile(path, []byte(gap), 0o600); err != nil {
+		t.Fatalf("failed to write ladder: %v", err)
+	}
+	if _, err := ladder.Load(path); err == nil {
+		t.Fatalf("expected an error for a ladder with a gap")
+	}
+}
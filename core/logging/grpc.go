@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// UnaryServerInterceptor gives every RPC a logger carrying its request ID, method, client and event, and logs the
+// outcome of the RPC. The request ID is sent back in the response header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error,
+	) {
+		start := time.Now()
+		ctx = startRPC(ctx, info.FullMethod, req)
+		resp, err := handler(ctx, req)
+		endRPC(ctx, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor gives every stream a logger like UnaryServerInterceptor, without an event
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := startRPC(ss.Context(), info.FullMethod, nil)
+		err := handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+		endRPC(ctx, start, err)
+		return err
+	}
+}
+
+// loggedStream is a server stream carrying the request logger in its context
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+// startRPC returns the context of an RPC with its logger
+func startRPC(ctx context.Context, fullMethod string, req interface{}) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := ""
+	if ids := md.Get(RequestIDKey); len(ids) > 0 {
+		requestID = ids[0]
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID)); err != nil {
+		FromContext(ctx).WithError(err).Debug("request_id_header_failed")
+	}
+
+	fields := logrus.Fields{FieldRequestID: requestID, FieldMethod: fullMethod}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[FieldClient] = p.Addr.String()
+	}
+	if eventID := EventIDOf(req); eventID != "" {
+		fields[FieldEventID] = eventID
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		fields[FieldTraceID] = span.TraceID().String()
+	}
+	return WithFields(ctx, fields)
+}
+
+// endRPC logs the outcome of an RPC, failures the server is responsible for as errors
+func endRPC(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	logger := FromContext(ctx).WithField("code", code.String()).WithField("duration", time.Since(start))
+	switch code {
+	case codes.OK:
+		logger.Debug("request_handled")
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		logger.WithError(err).Error("request_failed")
+	default:
+		logger.WithError(err).Warn("request_failed")
+	}
+}
+
+// EventIDOf returns the ID of the event a request is about, if any
+func EventIDOf(req interface{}) string {
+	switch r := req.(type) {
+	case interface{ GetEventID() string }:
+		return r.GetEventID()
+	case interface{ GetEvent() *model.Event }:
+		return r.GetEvent().GetID()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware gives every HTTP request to a named route a logger like UnaryServerInterceptor, the method being the
+// name of the route and the event the id path variable, and logs the outcome of the request. The request ID is sent
+// back in the X-Request-ID header.
+func Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil || route.GetName() == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			requestID := r.Header.Get(RequestIDKey)
+			if requestID == "" {
+				requestID = NewRequestID()
+			}
+			w.Header().Set(RequestIDKey, requestID)
+
+			fields := logrus.Fields{
+				FieldRequestID: requestID,
+				FieldMethod:    route.GetName(),
+				FieldClient:    r.RemoteAddr,
+			}
+			if id := mux.Vars(r)["id"]; id != "" {
+				fields[FieldEventID] = id
+			}
+			ctx := WithFields(r.Context(), fields)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			logger := FromContext(ctx).WithField("status", recorder.status).WithField("duration", time.Since(start))
+			switch {
+			case recorder.status >= http.StatusInternalServerError:
+				logger.Error("request_failed")
+			case recorder.status >= http.StatusBadRequest:
+				logger.Warn("request_failed")
+			default:
+				logger.Debug("request_handled")
+			}
+		})
+	}
+}
+
+// statusRecorder records the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,51 @@
+// Package logging carries a logger through the context of a request, so every log line written while handling it can
+// be tied back to the request: its ID, method, client and event
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDKey is the metadata key, or HTTP header, carrying the ID of a request. IDs sent by callers are kept so a
+// request can be followed across services, requests without one are given a new ID.
+const RequestIDKey = "x-request-id"
+
+// Fields of the request loggers
+const (
+	FieldRequestID = "request_id"
+	FieldMethod    = "method"
+	FieldClient    = "client"
+	FieldEventID   = "event_id"
+	FieldTraceID   = "trace_id"
+)
+
+type loggerKey struct{}
+
+// FromContext returns the logger of the request handled by the context, or the standard logger outside of requests
+func FromContext(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// WithLogger returns a context carrying the logger
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// WithFields returns a context whose logger also writes the fields
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithFields(fields))
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // never fails, see crypto/rand.Read
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func TestFromContext(t *testing.T) {
+	if logger := logging.FromContext(context.Background()); logger.Logger != logrus.StandardLogger() {
+		t.Fatalf("expected the standard logger outside of requests")
+	}
+
+	ctx := logging.WithFields(context.Background(), logrus.Fields{"a": 1})
+	ctx = logging.WithFields(ctx, logrus.Fields{"b": 2})
+	if fields := logging.FromContext(ctx).Data; fields["a"] != 1 || fields["b"] != 2 {
+		t.Fatalf("expected the fields to add up, got %v", fields)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	interceptor := logging.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: core.Service_Update_FullMethodName}
+	req := &core.UpdateRequest{Event: &model.Event{ID: "evt-1"}}
+
+	var fields logrus.Fields
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		fields = logging.FromContext(ctx).Data
+		logging.FromContext(ctx).Error("failed to merge event")
+		return nil, status.Error(codes.Internal, "merge failed")
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(logging.RequestIDKey, "req-1"))
+	if _, err := interceptor(ctx, req, info, handler); err == nil {
+		t.Fatalf("expected the error of the handler")
+	}
+	if fields[logging.FieldRequestID] != "req-1" || fields[logging.FieldEventID] != "evt-1" ||
+		fields[logging.FieldMethod] != core.Service_Update_FullMethodName {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 || entries[0].Data[logging.FieldRequestID] != "req-1" ||
+		entries[1].Message != "request_failed" || entries[1].Data["code"] != codes.Internal.String() {
+		t.Fatalf("expected the handler log and the outcome to be logged for the request, got %v", entries)
+	}
+
+	_, err := interceptor(context.Background(), &core.GetSportEventRequest{EventID: "evt-2"}, info, handler)
+	if err == nil {
+		t.Fatalf("expected the error of the handler")
+	}
+	if id, _ := fields[logging.FieldRequestID].(string); id == "" || id == "req-1" {
+		t.Fatalf("expected a new request ID, got %q", id)
+	}
+	if fields[logging.FieldEventID] != "evt-2" {
+		t.Fatalf("expected the event ID of the request, got %v", fields[logging.FieldEventID])
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var fields logrus.Fields
+	router := mux.NewRouter()
+	router.Use(logging.Middleware())
+	router.HandleFunc("/events/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fields = logging.FromContext(r.Context()).Data
+		w.WriteHeader(http.StatusNoContent)
+	}).Name(core.Service_Update_FullMethodName)
+
+	req := httptest.NewRequest(http.MethodPut, "/events/evt-1", nil)
+	req.Header.Set(logging.RequestIDKey, "req-1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Header().Get(logging.RequestIDKey) != "req-1" {
+		t.Fatalf("expected the request ID to be sent back, got %q", rec.Header().Get(logging.RequestIDKey))
+	}
+	if fields[logging.FieldRequestID] != "req-1" || fields[logging.FieldEventID] != "evt-1" ||
+		fields[logging.FieldMethod] != core.Service_Update_FullMethodName {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	if a, b := logging.NewRequestID(), logging.NewRequestID(); a == b || len(a) != 16 {
+		t.Fatalf("expected distinct 16 character IDs, got %q and %q", a, b)
+	}
+}
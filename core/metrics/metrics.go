@@ -0,0 +1,128 @@
+// Package metrics exposes Prometheus metrics of the core service: gRPC requests, repository calls, merges, transforms
+// and the events they update
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "core"
+
+// Results of calls to upstreams
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Kinds of event updates counted by EventUpdates
+const (
+	UpdateCreated      = "created"
+	UpdateUpdated      = "updated"
+	UpdateStaleDropped = "stale_dropped"
+	UpdateStalePartial = "stale_partial"
+	UpdateDuplicate    = "duplicate"
+)
+
+// Reasons requests are rejected for, counted by RequestRejected
+const (
+	RejectedRateLimited = "rate_limited"
+	RejectedShed        = "shed"
+)
+
+// Registry holds every metric of the service, along with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Time taken to handle gRPC requests, by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	repositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_duration_seconds",
+		Help:      "Time taken by repository calls, by operation and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	mergeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "merge_duration_seconds",
+		Help:      "Time taken to merge events, by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	transformDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transform_duration_seconds",
+		Help:      "Time taken by transforms, by transform and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"transform", "result"})
+
+	eventUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_updates_total",
+		Help:      "Event updates applied, by kind: created, updated, stale_dropped or stale_partial.",
+	}, []string{"kind"})
+
+	rejectedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_rejected_total",
+		Help:      "Requests rejected before being handled, by method and reason: rate_limited or shed.",
+	}, []string{"method", "reason"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		grpcRequests,
+		grpcDuration,
+		repositoryDuration,
+		mergeDuration,
+		transformDuration,
+		eventUpdates,
+		rejectedRequests,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// EventUpdated counts an event update of the given kind
+func EventUpdated(kind string) {
+	eventUpdates.WithLabelValues(kind).Inc()
+}
+
+// RequestRejected counts a request to a method rejected for the given reason
+func RequestRejected(method, reason string) {
+	rejectedRequests.WithLabelValues(method, reason).Inc()
+}
+
+// result returns the result label of a call
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
+
+// since returns the seconds elapsed since start
+func since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -0,0 +1,124 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository/mock"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// scrape returns the metrics as served on /metrics
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+func expectMetric(t *testing.T, want string) {
+	t.Helper()
+	if got := scrape(t); !strings.Contains(got, want) {
+		t.Fatalf("expected metric %q in\n%v", want, got)
+	}
+}
+
+// scopedTransform is a transform that only applies to soccer events
+type scopedTransform struct{}
+
+func (scopedTransform) TransformEvent(_ context.Context, _, fullModel *model.Event) (*model.Event, error) {
+	return &model.Event{ID: fullModel.GetID(), Name: &model.OptionalString{Value: "Scoped"}}, nil
+}
+
+func (scopedTransform) GetName() string {
+	return "Scoped"
+}
+
+func (scopedTransform) EventTypeIDs() []string {
+	return []string{"soccer"}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the handler error to be returned, got %v", err)
+	}
+
+	expectMetric(t, `core_grpc_requests_total{code="NotFound",method="/test.Service/Method"} 1`)
+	expectMetric(t, `core_grpc_request_duration_seconds_count{code="NotFound",method="/test.Service/Method"} 1`)
+}
+
+func TestInstrumentRepositoryAndMerger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	repo.EXPECT().GetEventByID(gomock.Any(), "evt-1").Return(nil, errors.New("unavailable"))
+
+	if _, err := metrics.InstrumentRepository(repo).GetEventByID(context.Background(), "evt-1"); err == nil {
+		t.Fatalf("expected the repository error to be returned")
+	}
+	expectMetric(t, `core_repository_duration_seconds_count{operation="GetEventByID",result="error"} 1`)
+
+	client := metrics.InstrumentMerger(merger.NewInlineMergerClient())
+	event := &model.Event{ID: "evt-1"}
+	if _, err := client.MergeEvent(context.Background(), event, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectMetric(t, `core_merge_duration_seconds_count{result="ok"} 1`)
+}
+
+func TestInstrumentTransformAndPipeline(t *testing.T) {
+	transform := metrics.InstrumentTransform(scopedTransform{})
+	if scoped, ok := transform.(transforms.Scoped); !ok || len(scoped.EventTypeIDs()) != 1 {
+		t.Fatalf("expected the event types of the transform to be passed through")
+	}
+
+	pipeline := transforms.NewSequentialPipeline(transform)
+	if err := metrics.RegisterPipeline(pipeline); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	soccer := &model.Event{ID: "evt-1", EventTypeID: &model.OptionalString{Value: "soccer"}}
+	racing := &model.Event{ID: "evt-2", EventTypeID: &model.OptionalString{Value: "horse_racing"}}
+	for _, event := range []*model.Event{soccer, racing} {
+		if _, _, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(), event, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expectMetric(t, `core_transform_duration_seconds_count{result="ok",transform="Scoped"} 1`)
+	expectMetric(t, `core_transform_runs_total{transform="Scoped"} 1`)
+	expectMetric(t, `core_transform_skipped_total{transform="Scoped"} 1`)
+	expectMetric(t, `core_transform_failures_total{transform="Scoped"} 0`)
+}
+
+func TestEventUpdated(t *testing.T) {
+	metrics.EventUpdated(metrics.UpdateCreated)
+	metrics.EventUpdated(metrics.UpdateUpdated)
+	metrics.EventUpdated(metrics.UpdateUpdated)
+
+	expectMetric(t, `core_event_updates_total{kind="created"} 1`)
+	expectMetric(t, `core_event_updates_total{kind="updated"} 2`)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+)
+
+var (
+	transformRunsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "transform", "runs_total"),
+		"Times a transform of the pipeline ran.", []string{"transform"}, nil)
+	transformSkippedDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "transform", "skipped_total"),
+		"Times a transform of the pipeline was skipped as it did not apply to the event.", []string{"transform"}, nil)
+	transformFailuresDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "transform", "failures_total"),
+		"Times a transform of the pipeline failed.", []string{"transform"}, nil)
+)
+
+// pipelineCollector exports the stats of a transform pipeline
+type pipelineCollector struct {
+	pipeline *transforms.Pipeline
+}
+
+// RegisterPipeline exports how often each transform of a pipeline runs, is skipped and fails
+func RegisterPipeline(pipeline *transforms.Pipeline) error {
+	return Registry.Register(&pipelineCollector{pipeline: pipeline})
+}
+
+func (c *pipelineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- transformRunsDesc
+	ch <- transformSkippedDesc
+	ch <- transformFailuresDesc
+}
+
+func (c *pipelineCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range c.pipeline.Stats() {
+		ch <- prometheus.MustNewConstMetric(transformRunsDesc, prometheus.CounterValue, float64(stats.Runs),
+			stats.Transform)
+		ch <- prometheus.MustNewConstMetric(transformSkippedDesc, prometheus.CounterValue, float64(stats.Skipped),
+			stats.Transform)
+		ch <- prometheus.MustNewConstMetric(transformFailuresDesc, prometheus.CounterValue, float64(stats.Failures),
+			stats.Transform)
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+type instrumentedRepository struct {
+	repo repository.Repository
+}
+
+// InstrumentRepository records the latency and result of every call to a repository
+func InstrumentRepository(repo repository.Repository) repository.Repository {
+	return &instrumentedRepository{repo: repo}
+}
+
+func (r *instrumentedRepository) HealthCheck(ctx context.Context) bool {
+	start := time.Now()
+	healthy := r.repo.HealthCheck(ctx)
+	res := ResultOK
+	if !healthy {
+		res = ResultError
+	}
+	repositoryDuration.WithLabelValues("HealthCheck", res).Observe(since(start))
+	return healthy
+}
+
+func (r *instrumentedRepository) GetEventByID(ctx context.Context, id string) (*model.Event, error) {
+	start := time.Now()
+	event, err := r.repo.GetEventByID(ctx, id)
+	repositoryDuration.WithLabelValues("GetEventByID", result(err)).Observe(since(start))
+	return event, err
+}
+
+func (r *instrumentedRepository) UpdateEvent(ctx context.Context, event *model.Event) error {
+	start := time.Now()
+	err := r.repo.UpdateEvent(ctx, event)
+	repositoryDuration.WithLabelValues("UpdateEvent", result(err)).Observe(since(start))
+	return err
+}
+
+func (r *instrumentedRepository) DeleteEventByID(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.repo.DeleteEventByID(ctx, id)
+	repositoryDuration.WithLabelValues("DeleteEventByID", result(err)).Observe(since(start))
+	return err
+}
+
+func (r *instrumentedRepository) GetEventIDsByStartTime(ctx context.Context, from, to time.Time) ([]string, error) {
+	start := time.Now()
+	ids, err := r.repo.GetEventIDsByStartTime(ctx, from, to)
+	repositoryDuration.WithLabelValues("GetEventIDsByStartTime", result(err)).Observe(since(start))
+	return ids, err
+}
+
+type instrumentedMerger struct {
+	client merger.ServiceClient
+}
+
+// InstrumentMerger records the latency and result of every merge
+func InstrumentMerger(client merger.ServiceClient) merger.ServiceClient {
+	return &instrumentedMerger{client: client}
+}
+
+func (m *instrumentedMerger) MergeEvent(ctx context.Context, left, right *model.Event) (*model.Event, error) {
+	start := time.Now()
+	event, err := m.client.MergeEvent(ctx, left, right)
+	mergeDuration.WithLabelValues(result(err)).Observe(since(start))
+	return event, err
+}
+
+// InstrumentTransform records the latency and result of every run of a transform, by its name
+func InstrumentTransform(transform transforms.TransformClient) transforms.TransformClient {
+	name := transform.GetName()
+	return transforms.Wrap(transform, func(ctx context.Context, partialUpdate, fullModel *model.Event) (
+		*model.Event, error,
+	) {
+		start := time.Now()
+		delta, err := transform.TransformEvent(ctx, partialUpdate, fullModel)
+		transformDuration.WithLabelValues(name, result(err)).Observe(since(start))
+		return delta, err
+	})
+}
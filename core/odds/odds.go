@@ -0,0 +1,100 @@
+// Package odds converts decimal prices into the other odds formats shown to punters
+package odds
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Fraction is a price in fractional (UK) format, e.g 5/2
+type Fraction struct {
+	Numerator   int
+	Denominator int
+}
+
+// Decimal returns the decimal price represented by the fraction
+func (f Fraction) Decimal() float64 {
+	return 1 + float64(f.Numerator)/float64(f.Denominator)
+}
+
+// String formats the fraction the way it is displayed to punters
+func (f Fraction) String() string {
+	if f.Denominator == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%d", f.Numerator, f.Denominator)
+}
+
+// ladder is the standard fractional ladder, ordered from the shortest to the longest price
+var ladder = []Fraction{
+	{1, 100}, {1, 50}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14}, {1, 12}, {1, 11}, {1, 10},
+	{1, 9}, {1, 8}, {2, 15}, {1, 7}, {2, 13}, {1, 6}, {2, 11}, {1, 5}, {2, 9}, {1, 4},
+	{2, 7}, {3, 10}, {1, 3}, {4, 11}, {2, 5}, {4, 9}, {1, 2}, {8, 15}, {4, 7}, {8, 13},
+	{4, 6}, {8, 11}, {4, 5}, {5, 6}, {10, 11}, {1, 1}, {21, 20}, {11, 10}, {6, 5}, {5, 4},
+	{11, 8}, {6, 4}, {13, 8}, {7, 4}, {15, 8}, {2, 1}, {85, 40}, {9, 4}, {5, 2}, {11, 4},
+	{3, 1}, {10, 3}, {7, 2}, {4, 1}, {9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2}, {7, 1},
+	{15, 2}, {8, 1}, {17, 2}, {9, 1}, {10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1}, {18, 1},
+	{20, 1}, {25, 1}, {33, 1}, {40, 1}, {50, 1}, {66, 1}, {80, 1}, {100, 1}, {150, 1}, {200, 1},
+	{250, 1}, {500, 1}, {1000, 1},
+}
+
+// Valid reports whether a decimal price can be converted, decimal prices must be greater than 1
+func Valid(decimal float64) bool {
+	return decimal > 1 && !math.IsInf(decimal, 0) && !math.IsNaN(decimal)
+}
+
+// ToFractional returns the fraction on the standard ladder that is nearest to the decimal price.
+// The zero Fraction is returned for invalid prices.
+func ToFractional(decimal float64) Fraction {
+	if !Valid(decimal) {
+		return Fraction{}
+	}
+
+	i := sort.Search(len(ladder), func(i int) bool {
+		return ladder[i].Decimal() >= decimal
+	})
+	if i == 0 {
+		return ladder[0]
+	}
+	if i == len(ladder) {
+		return ladder[len(ladder)-1]
+	}
+	if decimal-ladder[i-1].Decimal() < ladder[i].Decimal()-decimal {
+		return ladder[i-1]
+	}
+
+	return ladder[i]
+}
+
+// ToAmerican converts a decimal price to American (moneyline) odds, e.g 2.5 is +150 and 1.5 is -200.
+// Zero is returned for invalid prices.
+func ToAmerican(decimal float64) int {
+	if !Valid(decimal) {
+		return 0
+	}
+	if decimal >= 2 {
+		return int(math.Round((decimal - 1) * 100))
+	}
+
+	return int(math.Round(-100 / (decimal - 1)))
+}
+
+// FormatAmerican formats American odds with an explicit sign
+func FormatAmerican(american int) string {
+	if american == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%+d", american)
+}
+
+// ImpliedProbability returns the probability implied by a decimal price, in the range (0, 1)
+func ImpliedProbability(decimal float64) float64 {
+	if !Valid(decimal) {
+		return 0
+	}
+
+	return 1 / decimal
+}
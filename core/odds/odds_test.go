@@ -0,0 +1,54 @@
+package odds_test
+
+import (
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/odds"
+)
+
+func TestToFractional(t *testing.T) {
+	cases := map[float64]string{
+		2.0:    "1/1",
+		3.5:    "5/2",
+		1.5:    "1/2",
+		1.67:   "4/6",
+		11.0:   "10/1",
+		4.33:   "10/3",
+		1.01:   "1/100",
+		2000.0: "1000/1",
+		1.0:    "",
+		0.5:    "",
+	}
+
+	for decimal, want := range cases {
+		if got := odds.ToFractional(decimal).String(); got != want {
+			t.Fatalf("ToFractional(%v): expected %q, got %q", decimal, want, got)
+		}
+	}
+}
+
+func TestToAmerican(t *testing.T) {
+	cases := map[float64]string{
+		2.5:  "+150",
+		2.0:  "+100",
+		1.5:  "-200",
+		1.25: "-400",
+		11.0: "+1000",
+		1.0:  "",
+	}
+
+	for decimal, want := range cases {
+		if got := odds.FormatAmerican(odds.ToAmerican(decimal)); got != want {
+			t.Fatalf("ToAmerican(%v): expected %q, got %q", decimal, want, got)
+		}
+	}
+}
+
+func TestImpliedProbability(t *testing.T) {
+	if got := odds.ImpliedProbability(4); got != 0.25 {
+		t.Fatalf("expected %v, got %v", 0.25, got)
+	}
+	if got := odds.ImpliedProbability(0.5); got != 0 {
+		t.Fatalf("expected 0 for an invalid price, got %v", got)
+	}
+}
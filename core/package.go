@@ -2,13 +2,24 @@
 package core
 
 import (
+	"strconv"
 	"time"
 
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/odds"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
 //go:generate ./gen-proto.sh
 
+// Price movements reported on SelectionPrice.Movement
+const (
+	MovementFirming  = "Firming"
+	MovementDrifting = "Drifting"
+)
+
+// MaxPriceChanges is the number of most recent price changes reported on SelectionPrice.Changes
+const MaxPriceChanges = 10
+
 // ConvertFromModel converts a model.Event to a core.SportEvent
 func (to *SportEvent) ConvertFromModel(model *model.Event) {
 	to.ID = model.ID
@@ -22,3 +33,51 @@ func (to *SportEvent) ConvertFromModel(model *model.Event) {
 	to.Round = model.GetSportData().GetRound().GetValue()
 	to.Region = model.GetSportData().GetRegion().GetValue()
 }
+
+// ConvertPricesFromModel fills in the prices of every priced selection of a model.Event, displayed in the given format
+func (to *SportEvent) ConvertPricesFromModel(model *model.Event, format OddsFormat) {
+	to.Prices = nil
+	for _, market := range model.GetMarkets() {
+		for _, selection := range market.GetSelections() {
+			decimal := selection.GetPrice().GetValue()
+			if selection.GetPrice().GetDeleted() || !odds.Valid(decimal) {
+				continue // no price to convert
+			}
+
+			price := &SelectionPrice{
+				MarketID:           market.GetID(),
+				SelectionID:        selection.GetID(),
+				Decimal:            decimal,
+				Fractional:         odds.ToFractional(decimal).String(),
+				American:           odds.FormatAmerican(odds.ToAmerican(decimal)),
+				ImpliedProbability: odds.ImpliedProbability(decimal),
+				OpeningPrice:       selection.GetPriceHistory().GetOpeningPrice().GetValue(),
+				PreviousPrice:      selection.GetPriceHistory().GetPreviousPrice().GetValue(),
+			}
+			price.Movement = movement(price.PreviousPrice, decimal)
+			changes := selection.GetPriceHistory().GetChanges()
+			price.Changes = changes[max(0, len(changes)-MaxPriceChanges):]
+			switch format {
+			case OddsFormat_OddsFractional:
+				price.Display = price.Fractional
+			case OddsFormat_OddsAmerican:
+				price.Display = price.American
+			default:
+				price.Display = strconv.FormatFloat(decimal, 'f', 2, 64)
+			}
+			to.Prices = append(to.Prices, price)
+		}
+	}
+}
+
+// movement describes which way a price moved, a shortening price is firming and a lengthening price is drifting
+func movement(previous, current float64) string {
+	switch {
+	case previous == 0 || previous == current:
+		return ""
+	case current < previous:
+		return MovementFirming
+	default:
+		return MovementDrifting
+	}
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+)
+
+// RetryAfterKey is the metadata key, or HTTP header, telling a rejected caller how many seconds to wait before retrying
+const RetryAfterKey = "retry-after"
+
+// shedRetryAfter is the wait suggested to callers whose requests are shed
+const shedRetryAfter = time.Second
+
+// UnaryServerInterceptor rejects requests over the limit of their client with ResourceExhausted, and sheds requests
+// with Unavailable while too many are in flight. The health and reflection services are never limited.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error,
+	) {
+		if exempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if err := l.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		release, ok := l.Acquire()
+		if !ok {
+			return nil, shed(ctx, info.FullMethod)
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects streams over the limit of their client. Streams are long-lived and do not hold
+// one of the concurrency slots.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if exempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if err := l.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check takes a token for the client of the request, returning a ResourceExhausted status when none is left
+func (l *Limiter) check(ctx context.Context, method string) error {
+	client := clientFromContext(ctx)
+	allowed, wait := l.Allow(method, client)
+	if allowed {
+		return nil
+	}
+
+	metrics.RequestRejected(method, metrics.RejectedRateLimited)
+	logging.FromContext(ctx).WithField("client", client).Debug("request_rate_limited")
+	setRetryAfter(ctx, wait)
+	return status.Errorf(codes.ResourceExhausted, "rate limit of %v exceeded, retry in %v", method,
+		wait.Round(time.Millisecond))
+}
+
+// shed returns the status of a request shed to protect the repository
+func shed(ctx context.Context, method string) error {
+	metrics.RequestRejected(method, metrics.RejectedShed)
+	logging.FromContext(ctx).Debug("request_shed")
+	setRetryAfter(ctx, shedRetryAfter)
+	return status.Error(codes.Unavailable, "the service is overloaded, retry later")
+}
+
+func setRetryAfter(ctx context.Context, wait time.Duration) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RetryAfterKey, retryAfterSeconds(wait))); err != nil {
+		logging.FromContext(ctx).WithError(err).Debug("retry_after_header_failed")
+	}
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, as Retry-After has no finer precision
+func retryAfterSeconds(wait time.Duration) string {
+	return fmt.Sprint(max(1, int(math.Ceil(wait.Seconds()))))
+}
+
+// clientFromContext identifies the caller by its authenticated name, or its address otherwise. Credentials that have
+// not been verified are ignored, a caller sending a new API key on every request must not get a new bucket each time.
+func clientFromContext(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return "principal:" + principal.Name
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return "addr:" + host(p.Addr.String())
+	}
+	return "unknown"
+}
+
+// host strips the port of an address, which changes with every connection of a client
+func host(address string) string {
+	if h, _, err := net.SplitHostPort(address); err == nil {
+		return h
+	}
+	return address
+}
+
+// exempt reports whether a method is never limited: the health and reflection services
+func exempt(method string) bool {
+	return strings.HasPrefix(method, "/grpc.")
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+)
+
+// ErrorWriter writes a rejection, as a gRPC status error, in the format of the HTTP API
+type ErrorWriter func(w http.ResponseWriter, err error)
+
+// Middleware applies the limits of the gRPC method a route is named after to its requests, and sheds requests while
+// too many are in flight. Rejections carry a Retry-After header.
+func (l *Limiter) Middleware(writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := ""
+			if route := mux.CurrentRoute(r); route != nil {
+				method = route.GetName()
+			}
+
+			client := clientFromRequest(r)
+			if allowed, wait := l.Allow(method, client); !allowed {
+				metrics.RequestRejected(method, metrics.RejectedRateLimited)
+				w.Header().Set(RetryAfterKey, retryAfterSeconds(wait))
+				writeError(w, status.Errorf(codes.ResourceExhausted, "rate limit of %v exceeded", method))
+				return
+			}
+
+			release, ok := l.Acquire()
+			if !ok {
+				metrics.RequestRejected(method, metrics.RejectedShed)
+				w.Header().Set(RetryAfterKey, retryAfterSeconds(shedRetryAfter))
+				writeError(w, status.Error(codes.Unavailable, "the service is overloaded, retry later"))
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientFromRequest identifies the caller like clientFromContext
+func clientFromRequest(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.Name
+	}
+	return "addr:" + host(r.RemoteAddr)
+}
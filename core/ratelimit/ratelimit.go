@@ -0,0 +1,183 @@
+// Package ratelimit protects the core service from callers sending more than their share of requests, with token
+// buckets per client and method, and from overloading the repository, by shedding requests when its latency spikes
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long the bucket of a client is kept after it has refilled, so the buckets of clients that went
+// away are dropped
+const idleBucketTTL = time.Minute
+
+// Limit is a token bucket allowing Rate requests per second on average, in bursts of up to Burst requests
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Config configures a Limiter
+type Config struct {
+	Methods       map[string]Limit // limits of each client by full method name, methods without one are not limited
+	MaxConcurrent int              // requests handled at once across all clients, unbounded when zero
+	LatencyTarget time.Duration    // repository latency above which MaxConcurrent is scaled down, never when zero
+	Now           func() time.Time
+}
+
+// Limiter rate limits the requests of every client to every method, and sheds requests once too many are in flight
+type Limiter struct {
+	methods map[string]Limit
+	now     func() time.Time
+	shedder *shedder
+
+	mtx       sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type bucketKey struct {
+	method string
+	client string
+}
+
+// bucket holds the tokens left to a client for a method
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New creates a Limiter
+func New(config Config) *Limiter {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Limiter{
+		methods: config.Methods,
+		now:     config.Now,
+		shedder: &shedder{max: config.MaxConcurrent, target: config.LatencyTarget},
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of the client for the method. When the bucket is empty it returns false and how
+// long until a token is available.
+func (l *Limiter) Allow(method, client string) (bool, time.Duration) {
+	limit, ok := l.methods[method]
+	if !ok {
+		return true, 0
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := bucketKey{method: method, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if limit.Rate <= 0 {
+		return false, idleBucketTTL
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have been full for a while, the lock must be held
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		limit := l.methods[key.method]
+		if limit.Rate > 0 && now.Sub(b.last).Seconds()*limit.Rate >= float64(limit.Burst) &&
+			now.Sub(b.last) >= idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Acquire takes one of the concurrency slots, returning false when the request must be shed. The slot is released
+// by calling release once the request is handled.
+func (l *Limiter) Acquire() (release func(), ok bool) {
+	return l.shedder.acquire()
+}
+
+// ObserveLatency feeds the latency of a repository call to the load shedder
+func (l *Limiter) ObserveLatency(d time.Duration) {
+	l.shedder.observe(d)
+}
+
+// ConcurrencyLimit returns how many requests are currently allowed at once, zero when unbounded
+func (l *Limiter) ConcurrencyLimit() int {
+	return l.shedder.limit()
+}
+
+// latencyWeight is the weight of a new observation in the moving average of the repository latency
+const latencyWeight = 0.2
+
+// shedder bounds the requests in flight, lowering the bound as the repository slows down so a struggling repository
+// gets fewer requests instead of a growing queue of them
+type shedder struct {
+	max    int
+	target time.Duration
+
+	mtx      sync.Mutex
+	inFlight int
+	latency  float64 // moving average of the repository latency, in nanoseconds
+}
+
+func (s *shedder) observe(d time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.latency == 0 {
+		s.latency = float64(d)
+		return
+	}
+	s.latency += latencyWeight * (float64(d) - s.latency)
+}
+
+// limit scales the maximum down by how far the repository latency is over its target, keeping at least one slot
+func (s *shedder) limit() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.limitLocked()
+}
+
+func (s *shedder) limitLocked() int {
+	if s.max <= 0 || s.target <= 0 || s.latency <= float64(s.target) {
+		return s.max
+	}
+	return max(1, int(float64(s.max)*float64(s.target)/s.latency))
+}
+
+func (s *shedder) acquire() (func(), bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.max > 0 && s.inFlight >= s.limitLocked() {
+		return nil, false
+	}
+
+	s.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mtx.Lock()
+			s.inFlight--
+			s.mtx.Unlock()
+		})
+	}, true
+}
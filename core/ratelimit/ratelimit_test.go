@@ -0,0 +1,200 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ratelimit"
+)
+
+// clock is a fake time source advanced by the tests
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	limiter := ratelimit.New(ratelimit.Config{
+		Methods: map[string]ratelimit.Limit{core.Service_Update_FullMethodName: {Rate: 2, Burst: 3}},
+		Now:     c.Now,
+	})
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow(core.Service_Update_FullMethodName, "feed-a"); !allowed {
+			t.Fatalf("expected request %d of the burst to be allowed", i)
+		}
+	}
+	allowed, wait := limiter.Allow(core.Service_Update_FullMethodName, "feed-a")
+	if allowed || wait != 500*time.Millisecond {
+		t.Fatalf("expected the request to wait 500ms for a token, got %v and %v", allowed, wait)
+	}
+
+	if allowed, _ := limiter.Allow(core.Service_Update_FullMethodName, "feed-b"); !allowed {
+		t.Fatalf("expected clients to have their own buckets")
+	}
+	if allowed, _ := limiter.Allow(core.Service_GetSportEvent_FullMethodName, "feed-a"); !allowed {
+		t.Fatalf("expected methods without a limit not to be limited")
+	}
+
+	c.now = c.now.Add(500 * time.Millisecond)
+	if allowed, _ := limiter.Allow(core.Service_Update_FullMethodName, "feed-a"); !allowed {
+		t.Fatalf("expected a token to be refilled")
+	}
+}
+
+func TestLimiter_Shedding(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{MaxConcurrent: 4, LatencyTarget: 10 * time.Millisecond})
+
+	limiter.ObserveLatency(5 * time.Millisecond)
+	if got := limiter.ConcurrencyLimit(); got != 4 {
+		t.Fatalf("expected the full limit while the repository is fast, got %d", got)
+	}
+	var releases []func()
+	for i := 0; i < 4; i++ {
+		release, ok := limiter.Acquire()
+		if !ok {
+			t.Fatalf("expected slot %d to be acquired", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := limiter.Acquire(); ok {
+		t.Fatalf("expected requests over the limit to be shed")
+	}
+	for _, release := range releases {
+		release()
+	}
+
+	for i := 0; i < 20; i++ {
+		limiter.ObserveLatency(40 * time.Millisecond)
+	}
+	if got := limiter.ConcurrencyLimit(); got != 1 {
+		t.Fatalf("expected the limit to shrink as the repository slows down, got %d", got)
+	}
+	release, ok := limiter.Acquire()
+	if !ok {
+		t.Fatalf("expected one slot to be left")
+	}
+	if _, ok := limiter.Acquire(); ok {
+		t.Fatalf("expected the second request to be shed")
+	}
+	release()
+	release() // releasing twice frees a single slot
+	if _, ok := limiter.Acquire(); !ok {
+		t.Fatalf("expected the slot to be released")
+	}
+}
+
+// sportEventServer answers GetSportEvent with an empty response
+type sportEventServer struct {
+	core.UnimplementedServiceServer
+}
+
+func (sportEventServer) GetSportEvent(context.Context, *core.GetSportEventRequest) (*core.GetSportEventResponse,
+	error,
+) {
+	return &core.GetSportEventResponse{}, nil
+}
+
+func TestLimiter_UnaryServerInterceptor(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Methods: map[string]ratelimit.Limit{core.Service_GetSportEvent_FullMethodName: {Rate: 0.5, Burst: 1}},
+	})
+
+	// callers sending the key "trusted-*" are authenticated under that name
+	authenticate := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if keys := md.Get("x-api-key"); len(keys) > 0 && strings.HasPrefix(keys[0], "trusted-") {
+			ctx = auth.WithPrincipal(ctx, &auth.Principal{Name: keys[0]})
+		}
+		return handler(ctx, req)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(authenticate, limiter.UnaryServerInterceptor()))
+	core.RegisterServiceServer(server, sportEventServer{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := core.NewServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "trusted-a")
+	if _, err := client.GetSportEvent(ctx, &core.GetSportEventRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var header metadata.MD
+	_, err = client.GetSportEvent(ctx, &core.GetSportEventRequest{}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if got := header.Get(ratelimit.RetryAfterKey); len(got) != 1 || got[0] != "2" {
+		t.Fatalf("expected to be told to retry in 2 seconds, got %v", got)
+	}
+
+	other := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "trusted-b")
+	if _, err := client.GetSportEvent(other, &core.GetSportEventRequest{}); err != nil {
+		t.Fatalf("expected another client not to be limited: %v", err)
+	}
+
+	// keys that are not authenticated share the bucket of the address they come from
+	for _, key := range []string{"random-1", "random-2"} {
+		unverified := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+		_, err = client.GetSportEvent(unverified, &core.GetSportEventRequest{})
+		if wantLimited := key == "random-2"; (status.Code(err) == codes.ResourceExhausted) != wantLimited {
+			t.Fatalf("expected %v to be limited: %v, got %v", key, wantLimited, err)
+		}
+	}
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Methods: map[string]ratelimit.Limit{core.Service_Update_FullMethodName: {Rate: 1, Burst: 1}},
+	})
+	router := mux.NewRouter()
+	router.Use(limiter.Middleware(func(w http.ResponseWriter, err error) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(status.Convert(err).Message()))
+	}))
+	router.HandleFunc("/events/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Name(core.Service_Update_FullMethodName)
+
+	do := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/events/evt-1", nil))
+		return rec
+	}
+	if rec := do(); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the first request to be allowed, got %d", rec.Code)
+	}
+	rec := do()
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected the second request to be limited, got %d with Retry-After %q", rec.Code,
+			rec.Header().Get("Retry-After"))
+	}
+}
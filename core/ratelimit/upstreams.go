@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+type observedRepository struct {
+	repository.Repository
+	limiter *Limiter
+}
+
+// ObserveRepository feeds the latency of the reads and writes of events to the load shedder of the limiter
+func ObserveRepository(repo repository.Repository, limiter *Limiter) repository.Repository {
+	return &observedRepository{Repository: repo, limiter: limiter}
+}
+
+func (r *observedRepository) GetEventByID(ctx context.Context, id string) (*model.Event, error) {
+	start := time.Now()
+	event, err := r.Repository.GetEventByID(ctx, id)
+	r.limiter.ObserveLatency(time.Since(start))
+	return event, err
+}
+
+func (r *observedRepository) UpdateEvent(ctx context.Context, event *model.Event) error {
+	start := time.Now()
+	err := r.Repository.UpdateEvent(ctx, event)
+	r.limiter.ObserveLatency(time.Since(start))
+	return err
+}
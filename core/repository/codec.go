@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Events are stored in an envelope: a header of three bytes, the envelope magic, the envelope version and the
+// compression of the payload, followed by the payload, the protobuf encoding of the event.
+//
+// Events written before the envelope are JSON objects, starting with '{', and are still read. They are rewritten in
+// an envelope the next time they are updated.
+const (
+	envelopeMagic   byte = 0x00 // never the first byte of a JSON value
+	envelopeVersion byte = 1
+	headerSize           = 3
+)
+
+// maxDecodedSize bounds the size of a decompressed event, in line with the gRPC message size limit
+const maxDecodedSize = 1024 * 1024 * 64
+
+// Compression is the compression of the events stored
+type Compression byte
+
+// Compressions supported, an event is read whatever compression it was written with
+const (
+	CompressionNone   Compression = 0
+	CompressionSnappy Compression = 1
+	CompressionZstd   Compression = 2
+)
+
+// ErrUnknownEnvelope is returned for a stored value in an envelope this version cannot read
+var ErrUnknownEnvelope = errors.New("unknown_envelope")
+
+// ParseCompression returns the compression of a name: none (or empty), snappy or zstd
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	case "zstd":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, fmt.Errorf("unknown compression %q, expected none, snappy or zstd", name)
+	}
+}
+
+// String returns the name of the compression
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("Compression(%d)", byte(c))
+	}
+}
+
+// codec encodes events in envelopes with a compression, and decodes envelopes and legacy JSON values
+type codec struct {
+	compression Compression
+	encoder     *zstd.Encoder
+	decoder     *zstd.Decoder
+}
+
+func newCodec(compression Compression) (*codec, error) {
+	if compression > CompressionZstd {
+		return nil, fmt.Errorf("unknown compression %v", compression)
+	}
+
+	// EncodeAll and DecodeAll are safe for concurrent use
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedSize))
+	if err != nil {
+		return nil, err
+	}
+	return &codec{compression: compression, encoder: encoder, decoder: decoder}, nil
+}
+
+// encode returns the envelope of an event
+func (c *codec) encode(event *model.Event) ([]byte, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{envelopeMagic, envelopeVersion, byte(c.compression)}
+	switch c.compression {
+	case CompressionSnappy:
+		return append(header, snappy.Encode(nil, payload)...), nil
+	case CompressionZstd:
+		return c.encoder.EncodeAll(payload, header), nil
+	default:
+		return append(header, payload...), nil
+	}
+}
+
+// decode returns the event of an envelope or of a legacy JSON value
+func (c *codec) decode(data []byte) (*model.Event, error) {
+	event := &model.Event{}
+	if len(data) == 0 || data[0] != envelopeMagic {
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	}
+
+	if len(data) < headerSize || data[1] != envelopeVersion {
+		return nil, ErrUnknownEnvelope
+	}
+	payload, err := c.decompress(Compression(data[2]), data[headerSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proto.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// decompress returns the payload of an envelope written with a compression
+func (c *codec) decompress(compression Compression, payload []byte) ([]byte, error) {
+	switch compression {
+	case CompressionNone:
+		return payload, nil
+	case CompressionSnappy:
+		size, err := snappy.DecodedLen(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress event: %w", err)
+		}
+		if size > maxDecodedSize {
+			return nil, fmt.Errorf("failed to decompress event: %d bytes is over the limit", size)
+		}
+		payload, err = snappy.Decode(nil, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress event: %w", err)
+		}
+		return payload, nil
+	case CompressionZstd:
+		payload, err := c.decoder.DecodeAll(payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress event: %w", err)
+		}
+		return payload, nil
+	default:
+		return nil, fmt.Errorf("%w: compression %d", ErrUnknownEnvelope, byte(compression))
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func testEvent() *model.Event {
+	return &model.Event{
+		ID:            "e002",
+		Name:          &model.OptionalString{Value: "Test Event"},
+		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingSuspended},
+		Markets: []*model.Market{
+			{
+				ID:   "mkt-1",
+				Name: &model.OptionalString{Value: "Head to Head"},
+				Selections: []*model.Selection{
+					{ID: "sel-1", Price: &model.OptionalDouble{Value: 1.80}},
+					{ID: "sel-2", Price: &model.OptionalDouble{Deleted: true}},
+				},
+			},
+		},
+	}
+}
+
+func Test_codec_RoundTrip(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionSnappy, CompressionZstd} {
+		t.Run(compression.String(), func(t *testing.T) {
+			c, err := newCodec(compression)
+			require.NoError(t, err)
+
+			data, err := c.encode(testEvent())
+			require.NoError(t, err)
+			assert.Equal(t, []byte{envelopeMagic, envelopeVersion, byte(compression)}, data[:headerSize])
+
+			// events are read whatever compression they were written with
+			reader, err := newCodec(CompressionNone)
+			require.NoError(t, err)
+			event, err := reader.decode(data)
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(testEvent(), event), "decoded %v", event)
+		})
+	}
+}
+
+func Test_codec_LegacyJSON(t *testing.T) {
+	data, err := json.Marshal(testEvent())
+	require.NoError(t, err)
+
+	c, err := newCodec(CompressionZstd)
+	require.NoError(t, err)
+	event, err := c.decode(data)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(testEvent(), event), "decoded %v", event)
+}
+
+func Test_codec_UnknownEnvelope(t *testing.T) {
+	c, err := newCodec(CompressionNone)
+	require.NoError(t, err)
+
+	_, err = c.decode([]byte{envelopeMagic, envelopeVersion + 1, byte(CompressionNone)})
+	assert.ErrorIs(t, err, ErrUnknownEnvelope)
+	_, err = c.decode([]byte{envelopeMagic, envelopeVersion, 9})
+	assert.ErrorIs(t, err, ErrUnknownEnvelope)
+	_, err = c.decode([]byte{envelopeMagic, envelopeVersion, byte(CompressionZstd), 1, 2, 3})
+	assert.Error(t, err)
+
+	_, err = newCodec(Compression(9))
+	assert.Error(t, err)
+}
+
+func Test_ParseCompression(t *testing.T) {
+	for name, want := range map[string]Compression{
+		"": CompressionNone, "none": CompressionNone, "snappy": CompressionSnappy, "zstd": CompressionZstd,
+	} {
+		got, err := ParseCompression(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseCompression("gzip")
+	assert.Error(t, err)
+}
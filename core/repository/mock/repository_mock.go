@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "git.neds.sh/technology/pricekinetics/tools/codetest/model"
 	gomock "go.uber.org/mock/gomock"
@@ -70,6 +71,21 @@ func (mr *MockRepositoryMockRecorder) GetEventByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventByID", reflect.TypeOf((*MockRepository)(nil).GetEventByID), ctx, id)
 }
 
+// GetEventIDsByStartTime mocks base method.
+func (m *MockRepository) GetEventIDsByStartTime(ctx context.Context, from, to time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventIDsByStartTime", ctx, from, to)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventIDsByStartTime indicates an expected call of GetEventIDsByStartTime.
+func (mr *MockRepositoryMockRecorder) GetEventIDsByStartTime(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventIDsByStartTime", reflect.TypeOf((*MockRepository)(nil).GetEventIDsByStartTime), ctx, from, to)
+}
+
 // HealthCheck mocks base method.
 func (m *MockRepository) HealthCheck(ctx context.Context) bool {
 	m.ctrl.T.Helper()
@@ -3,22 +3,41 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/sirupsen/logrus"
 
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
+// startTimeIndexKey is the sorted set of event IDs scored by their StartTime in unix milliseconds
+const startTimeIndexKey = "index:event_start_time"
+
+// maxTxAttempts is how many times a transaction is attempted when the keys it watches change
+const maxTxAttempts = 5
+
+// startTimeBackfilledKey is set once the events stored before the start time index existed have been added to it
+const startTimeBackfilledKey = "index:event_start_time:backfilled"
+
 type redisRepo struct {
 	client *redis.Client
+	codec  *codec
 }
 
-// NewRedisRepository creates a new instance of a Repository using Redis as the persistence layer
-func NewRedisRepository(ctx context.Context, address string, password string) (Repository, error) {
+// NewRedisRepository creates a new instance of a Repository using Redis as the persistence layer. Events are written
+// with the given compression, and read whatever compression or legacy JSON encoding they were written with.
+func NewRedisRepository(ctx context.Context, address string, password string, compression Compression) (
+	Repository, error,
+) {
+	codec, err := newCodec(compression)
+	if err != nil {
+		return nil, err
+	}
+
 	// Connect to Redis
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     address,
@@ -27,52 +46,104 @@ func NewRedisRepository(ctx context.Context, address string, password string) (R
 	})
 
 	// Check connection
-	rslt := &redisRepo{client: rdb}
+	rslt := &redisRepo{client: rdb, codec: codec}
 	rslt.HealthCheck(ctx)
 	if !rslt.HealthCheck(ctx) {
 		return nil, fmt.Errorf("failed_to_init_redis")
 	}
+	if err := rslt.backfillStartTimeIndex(ctx); err != nil {
+		return nil, err
+	}
 
-	return &redisRepo{client: rdb}, nil
+	return rslt, nil
 }
 
 func (c *redisRepo) HealthCheck(ctx context.Context) bool {
 	if err := c.client.Ping(ctx).Err(); err != nil {
-		logrus.Errorf("could not connect to Redis: %v", err)
+		logging.FromContext(ctx).WithError(err).Error("could not connect to Redis")
 		return false
 	}
 
 	return true
 }
 
+// UpdateEvent stores the event unless a higher sequence has been stored meanwhile. The sequence is compared and the
+// event written in one transaction, retried when the event changes in between.
 func (c *redisRepo) UpdateEvent(ctx context.Context, event *model.Event) error {
-	data, mErr := json.Marshal(event)
+	data, mErr := c.codec.encode(event)
 	if mErr != nil {
-		logrus.Errorf("could not marshall event %v", mErr)
+		logging.FromContext(ctx).WithError(mErr).Error("could not marshall event")
 		return mErr
 	}
-	rslt := c.client.Set(ctx, event.ID, data, 0)
-	if rslt.Err() != nil {
-		logrus.Errorf("could not update event %v", rslt.Err())
-		return rslt.Err()
+
+	var err error
+	for range maxTxAttempts {
+		err = c.client.Watch(ctx, func(tx *redis.Tx) error {
+			if err := c.checkSequence(ctx, tx, event); err != nil {
+				return err
+			}
+			_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, event.ID, data, 0)
+				if startTime := event.GetStartTime(); startTime != nil && !startTime.GetDeleted() {
+					score := float64(time.Unix(0, startTime.GetValue()).UnixMilli())
+					pipe.ZAdd(ctx, startTimeIndexKey, redis.Z{Score: score, Member: event.ID})
+				} else {
+					pipe.ZRem(ctx, startTimeIndexKey, event.ID)
+				}
+				return nil
+			})
+			return err
+		}, event.ID)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if errors.Is(err, ErrStaleSequence) {
+		logging.FromContext(ctx).WithField("id", event.ID).Info("event has a newer sequence")
+		return err
+	} else if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("could not update event")
+		return err
+	}
+
+	return nil
+}
+
+// checkSequence fails with ErrStaleSequence when the stored event has a higher sequence than the event
+func (c *redisRepo) checkSequence(ctx context.Context, tx *redis.Tx, event *model.Event) error {
+	if event.GetSequence() == nil {
+		return nil
 	}
 
+	data, err := tx.Get(ctx, event.ID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	stored, err := c.codec.decode(data)
+	if err != nil {
+		return err
+	}
+	if stored.GetSequence().GetValue() > event.GetSequence().GetValue() {
+		return ErrStaleSequence
+	}
 	return nil
 }
 
 func (c *redisRepo) GetEventByID(ctx context.Context, id string) (*model.Event, error) {
-	rslt, err := c.client.Get(ctx, id).Result()
+	rslt, err := c.client.Get(ctx, id).Bytes()
 	if errors.Is(err, redis.Nil) {
-		logrus.Infof("Event not found")
+		logging.FromContext(ctx).WithField("id", id).Info("Event not found")
 		return nil, nil
 	} else if err != nil {
-		logrus.Errorf("could not get event %v", err)
+		logging.FromContext(ctx).WithError(err).Error("could not get event")
 		return nil, err
 	}
 
-	event := &model.Event{}
-	if err := json.Unmarshal([]byte(rslt), event); err != nil {
-		logrus.Errorf("failed to unmarshal event %v", err)
+	event, err := c.codec.decode(rslt)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("failed to unmarshal event")
 		return nil, err
 	}
 
@@ -80,10 +151,83 @@ func (c *redisRepo) GetEventByID(ctx context.Context, id string) (*model.Event,
 }
 
 func (c *redisRepo) DeleteEventByID(ctx context.Context, id string) error {
-	_, err := c.client.Del(ctx, id).Result()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, id)
+		pipe.ZRem(ctx, startTimeIndexKey, id)
+		return nil
+	})
 	if err != nil {
-		logrus.Errorf("could not delete event %v", err)
+		logging.FromContext(ctx).WithError(err).Error("could not delete event")
 	}
 
 	return err
 }
+
+func (c *redisRepo) GetEventIDsByStartTime(ctx context.Context, from, to time.Time) ([]string, error) {
+	ids, err := c.client.ZRangeByScore(ctx, startTimeIndexKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.UnixMilli(), 10),
+		Max: strconv.FormatInt(to.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("could not get events by start time")
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// backfillStartTimeIndex adds the events stored before the start time index existed to it, once. Events are only added
+// when missing from the index, an event updated meanwhile has already indexed its current start time.
+func (c *redisRepo) backfillStartTimeIndex(ctx context.Context) error {
+	done, err := c.client.Exists(ctx, startTimeBackfilledKey).Result()
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("could not check the start time index")
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	indexed := 0
+	iter := c.client.ScanType(ctx, 0, "", 0, "string").Iterator()
+	for iter.Next(ctx) {
+		id := iter.Val()
+		if id == startTimeBackfilledKey {
+			continue
+		}
+		data, err := c.client.Get(ctx, id).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue // deleted since the scan
+		} else if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("could not get event")
+			return err
+		}
+		// the database may hold keys that are not events, they must not stop the service from starting
+		event, err := c.codec.decode(data)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("key", id).Warn("start_time_backfill_skipped_key")
+			continue
+		}
+		startTime := event.GetStartTime()
+		if startTime == nil || startTime.GetDeleted() {
+			continue
+		}
+		score := float64(time.Unix(0, startTime.GetValue()).UnixMilli())
+		if err := c.client.ZAddNX(ctx, startTimeIndexKey, redis.Z{Score: score, Member: id}).Err(); err != nil {
+			logging.FromContext(ctx).WithError(err).Error("could not index event start time")
+			return err
+		}
+		indexed++
+	}
+	if err := iter.Err(); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("could not scan events")
+		return err
+	}
+
+	if err := c.client.Set(ctx, startTimeBackfilledKey, time.Now().Unix(), 0).Err(); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("could not mark the start time index as backfilled")
+		return err
+	}
+	logging.FromContext(ctx).WithField("events", indexed).Info("start_time_index_backfilled")
+	return nil
+}
@@ -2,15 +2,19 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
 func Test_redisRepo_UpdateEvent(t *testing.T) {
-	repo, err := NewRedisRepository(context.Background(), "localhost:6379", "")
+	repo, err := NewRedisRepository(context.Background(), "localhost:6379", "", CompressionNone)
 	assert.NoError(t, err)
 	input := &model.Event{
 		ID:            "e001",
@@ -50,6 +54,89 @@ func Test_redisRepo_UpdateEvent(t *testing.T) {
 	assert.Equal(t, input.BettingStatus.Value, output.BettingStatus.Value)
 	assert.Equal(t, input.Markets[0].Name.Value, output.Markets[0].Name.Value)
 	assert.Equal(t, input.Markets[0].Selections[0].Name.Value, output.Markets[0].Selections[0].Name.Value)
+
+	startTime := time.Unix(0, input.StartTime.Value)
+	ids, idsErr := repo.GetEventIDsByStartTime(context.Background(), startTime.Add(-time.Minute), startTime)
+	assert.NoError(t, idsErr)
+	assert.Contains(t, ids, input.ID)
+
 	delErr := repo.DeleteEventByID(context.Background(), input.ID)
 	assert.NoError(t, delErr)
+	ids, idsErr = repo.GetEventIDsByStartTime(context.Background(), startTime.Add(-time.Minute), startTime)
+	assert.NoError(t, idsErr)
+	assert.NotContains(t, ids, input.ID)
+}
+
+func Test_redisRepo_UpdateEventStaleSequence(t *testing.T) {
+	repo, err := NewRedisRepository(context.Background(), "localhost:6379", "", CompressionNone)
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Sequence = &model.OptionalInt64{Value: 200}
+	require.NoError(t, repo.UpdateEvent(context.Background(), event))
+	defer func() { _ = repo.DeleteEventByID(context.Background(), event.ID) }()
+
+	older := testEvent()
+	older.Name = &model.OptionalString{Value: "Older name"}
+	older.Sequence = &model.OptionalInt64{Value: 100}
+	assert.ErrorIs(t, repo.UpdateEvent(context.Background(), older), ErrStaleSequence)
+
+	stored, err := repo.GetEventByID(context.Background(), event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, event.Name.Value, stored.Name.Value)
+	assert.Equal(t, int64(200), stored.Sequence.Value)
+
+	// the same sequence is written again, e.g a partially applied stale update
+	assert.NoError(t, repo.UpdateEvent(context.Background(), event))
+}
+
+func Test_redisRepo_BackfillsStartTimeIndex(t *testing.T) {
+	repo, err := NewRedisRepository(context.Background(), "localhost:6379", "", CompressionNone)
+	require.NoError(t, err)
+	client := repo.(*redisRepo).client
+
+	// an event stored before the index existed
+	event := testEvent()
+	event.StartTime = &model.OptionalInt64{Value: 1758244443000000000}
+	legacy, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(context.Background(), event.ID, legacy, 0).Err())
+	require.NoError(t, client.ZRem(context.Background(), startTimeIndexKey, event.ID).Err())
+	require.NoError(t, client.Del(context.Background(), startTimeBackfilledKey).Err())
+	defer func() { _ = repo.DeleteEventByID(context.Background(), event.ID) }()
+	// a key that is not an event
+	require.NoError(t, client.Set(context.Background(), "backfill-not-an-event", "not an event", 0).Err())
+	defer func() { _ = client.Del(context.Background(), "backfill-not-an-event").Err() }()
+
+	repo, err = NewRedisRepository(context.Background(), "localhost:6379", "", CompressionNone)
+	require.NoError(t, err)
+	startTime := time.Unix(0, event.StartTime.Value)
+	ids, err := repo.GetEventIDsByStartTime(context.Background(), startTime.Add(-time.Minute), startTime)
+	require.NoError(t, err)
+	assert.Contains(t, ids, event.ID)
+	assert.Equal(t, int64(1), client.Exists(context.Background(), startTimeBackfilledKey).Val())
+}
+
+func Test_redisRepo_MigratesLegacyJSON(t *testing.T) {
+	repo, err := NewRedisRepository(context.Background(), "localhost:6379", "", CompressionZstd)
+	require.NoError(t, err)
+	client := repo.(*redisRepo).client
+
+	legacy, err := json.Marshal(testEvent())
+	require.NoError(t, err)
+	require.NoError(t, client.Set(context.Background(), testEvent().ID, legacy, 0).Err())
+	defer func() { _ = repo.DeleteEventByID(context.Background(), testEvent().ID) }()
+
+	event, err := repo.GetEventByID(context.Background(), testEvent().ID)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(testEvent(), event), "read %v", event)
+
+	require.NoError(t, repo.UpdateEvent(context.Background(), event))
+	stored, err := client.Get(context.Background(), testEvent().ID).Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{envelopeMagic, envelopeVersion, byte(CompressionZstd)}, stored[:headerSize])
+
+	event, err = repo.GetEventByID(context.Background(), testEvent().ID)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(testEvent(), event), "read %v", event)
 }
@@ -2,16 +2,23 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
+// ErrStaleSequence is returned by UpdateEvent when the stored event has a higher sequence than the event written, a
+// newer update has been stored since the event was read
+var ErrStaleSequence = errors.New("stale_sequence")
+
 //go:generate mockgen -source repository.go -destination mock/repository_mock.go -package mock
 
 // Repository is an interface for something that can Retrieve, Update and remove Events from a persistence layer
 type Repository interface {
 	HealthCheck(ctx context.Context) bool
 	GetEventByID(ctx context.Context, id string) (*model.Event, error)
-	UpdateEvent(ctx context.Context, event *model.Event) error
+	UpdateEvent(ctx context.Context, event *model.Event) error // fails with ErrStaleSequence, see above
 	DeleteEventByID(ctx context.Context, id string) error
+	GetEventIDsByStartTime(ctx context.Context, from, to time.Time) ([]string, error)
 }
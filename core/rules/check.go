@@ -0,0 +1,157 @@
+package rules
+
+import "fmt"
+
+// kind is the type of the value of an expression, checked when a rule is compiled so a rule referring to fields
+// outside of its scope or comparing values of different kinds is rejected instead of failing on every update
+type kind int
+
+const (
+	kindNumber kind = iota + 1
+	kindString
+	kindBool
+	kindMarkets
+	kindSelections
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "condition"
+	case kindMarkets:
+		return "markets"
+	default:
+		return "selections"
+	}
+}
+
+// checkScope is the level fields are resolved on and the enclosing levels available, mirroring scope
+type checkScope struct {
+	level     string
+	market    bool
+	selection bool
+}
+
+// scopeOf returns the checkScope of the condition of a rule
+func scopeOf(ruleScope string) checkScope {
+	switch ruleScope {
+	case ScopeSelection:
+		return checkScope{level: ScopeSelection, market: true, selection: true}
+	case ScopeMarket:
+		return checkScope{level: ScopeMarket, market: true}
+	default:
+		return checkScope{level: ScopeEvent}
+	}
+}
+
+// checkCondition checks an expression evaluates to a condition
+func checkCondition(n node, c checkScope) error {
+	k, err := n.check(c)
+	if err != nil {
+		return err
+	}
+	if k != kindBool {
+		return fmt.Errorf("expected a condition, got a %v", k)
+	}
+	return nil
+}
+
+func (n *literalNode) check(_ checkScope) (kind, error) {
+	switch n.value.(type) {
+	case float64:
+		return kindNumber, nil
+	case bool:
+		return kindBool, nil
+	default:
+		return kindString, nil
+	}
+}
+
+func (n *pathNode) check(c checkScope) (kind, error) {
+	for _, parent := range n.parts[:len(n.parts)-1] {
+		switch parent {
+		case "event":
+			c = checkScope{level: ScopeEvent}
+		case "market":
+			if !c.market {
+				return 0, fmt.Errorf("market is not available from %v fields", c.level)
+			}
+			c = checkScope{level: ScopeMarket, market: true}
+		case "selection":
+			if !c.selection {
+				return 0, fmt.Errorf("selection is not available from %v fields", c.level)
+			}
+		}
+	}
+
+	field := n.parts[len(n.parts)-1]
+	k, ok := fields[c.level][field]
+	if !ok {
+		return 0, fmt.Errorf("%vs have no field %q", c.level, field)
+	}
+	return k, nil
+}
+
+func (n *notNode) check(c checkScope) (kind, error) {
+	if err := checkCondition(n.operand, c); err != nil {
+		return 0, fmt.Errorf("!: %w", err)
+	}
+	return kindBool, nil
+}
+
+func (n *logicalNode) check(c checkScope) (kind, error) {
+	for _, operand := range []node{n.left, n.right} {
+		if err := checkCondition(operand, c); err != nil {
+			return 0, fmt.Errorf("%v: %w", n.op, err)
+		}
+	}
+	return kindBool, nil
+}
+
+func (n *compareNode) check(c checkScope) (kind, error) {
+	left, err := n.left.check(c)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.check(c)
+	if err != nil {
+		return 0, err
+	}
+
+	if left != right || left == kindMarkets || left == kindSelections {
+		return 0, fmt.Errorf("cannot compare a %v with a %v", left, right)
+	}
+	if left == kindBool && n.op != "==" && n.op != "!=" {
+		return 0, fmt.Errorf("cannot compare conditions using %v", n.op)
+	}
+	return kindBool, nil
+}
+
+func (n *callNode) check(c checkScope) (kind, error) {
+	list, err := n.list.check(c)
+	if err != nil {
+		return 0, err
+	}
+	switch list {
+	case kindMarkets:
+		c = checkScope{level: ScopeMarket, market: true}
+	case kindSelections:
+		c = checkScope{level: ScopeSelection, market: true, selection: true}
+	default:
+		return 0, fmt.Errorf("%v needs markets or selections, got a %v", n.fn, list)
+	}
+
+	if n.predicate != nil {
+		if err := checkCondition(n.predicate, c); err != nil {
+			return 0, fmt.Errorf("%v: %w", n.fn, err)
+		}
+	}
+	if n.fn == "count" {
+		return kindNumber, nil
+	}
+	return kindBool, nil
+}
@@ -0,0 +1,364 @@
+package rules
+
+import (
+	"fmt"
+	"slices"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// value is the result of evaluating a node: a float64, string, bool, list of scopes or nil for missing values
+type value any
+
+// node is a compiled expression
+type node interface {
+	eval(s *scope) (value, error)
+	check(c checkScope) (kind, error)
+}
+
+// scope is the part of an event an expression is evaluated against, the innermost non nil level is the current one
+type scope struct {
+	event     *model.Event
+	market    *model.Market
+	selection *model.Selection
+}
+
+var (
+	// fields are the fields of every level of an event and their kinds
+	fields = map[string]map[string]kind{
+		ScopeEvent: {
+			"id": kindString, "name": kindString, "status": kindString, "eventTypeID": kindString,
+			"startTime": kindNumber, "sportName": kindString, "region": kindString, "league": kindString,
+			"round": kindString, "markets": kindMarkets,
+		},
+		ScopeMarket: {
+			"id": kindString, "name": kindString, "status": kindString, "startTime": kindNumber,
+			"overround": kindNumber, "selections": kindSelections,
+		},
+		ScopeSelection: {"id": kindString, "name": kindString, "status": kindString, "price": kindNumber},
+	}
+	parents   = []string{"event", "market", "selection"}
+	functions = []string{"any", "all", "count"}
+)
+
+func isField(name string) bool {
+	for _, levelFields := range fields {
+		if _, ok := levelFields[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isParent(name string) bool {
+	return slices.Contains(parents, name)
+}
+
+func isFunction(name string) bool {
+	return slices.Contains(functions, name)
+}
+
+func isStatus(name string) bool {
+	_, ok := model.BettingStatus_value[name]
+	return ok
+}
+
+// field resolves a field of the current level of the scope
+func (s *scope) field(name string) (value, error) {
+	switch {
+	case s.selection != nil:
+		return selectionField(s.selection, name)
+	case s.market != nil:
+		return marketField(s, name)
+	default:
+		return eventField(s, name)
+	}
+}
+
+func eventField(s *scope, name string) (value, error) {
+	e := s.event
+	switch name {
+	case "id":
+		return e.GetID(), nil
+	case "name":
+		return optionalString(e.GetName()), nil
+	case "status":
+		return optionalStatus(e.GetBettingStatus()), nil
+	case "eventTypeID":
+		return optionalString(e.GetEventTypeID()), nil
+	case "startTime":
+		return optionalInt64(e.GetStartTime()), nil
+	case "sportName":
+		return optionalString(e.GetSportData().GetName()), nil
+	case "region":
+		return optionalString(e.GetSportData().GetRegion()), nil
+	case "league":
+		return optionalString(e.GetSportData().GetLeague()), nil
+	case "round":
+		return optionalString(e.GetSportData().GetRound()), nil
+	case "markets":
+		list := make([]*scope, 0, len(e.GetMarkets()))
+		for _, m := range e.GetMarkets() {
+			list = append(list, &scope{event: e, market: m})
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("events have no field %q", name)
+}
+
+func marketField(s *scope, name string) (value, error) {
+	m := s.market
+	switch name {
+	case "id":
+		return m.GetID(), nil
+	case "name":
+		return optionalString(m.GetName()), nil
+	case "status":
+		return optionalStatus(m.GetBettingStatus()), nil
+	case "startTime":
+		return optionalInt64(m.GetStartTime()), nil
+	case "overround":
+		return optionalDouble(m.GetOverround()), nil
+	case "selections":
+		list := make([]*scope, 0, len(m.GetSelections()))
+		for _, sel := range m.GetSelections() {
+			list = append(list, &scope{event: s.event, market: m, selection: sel})
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("markets have no field %q", name)
+}
+
+func selectionField(sel *model.Selection, name string) (value, error) {
+	switch name {
+	case "id":
+		return sel.GetID(), nil
+	case "name":
+		return optionalString(sel.GetName()), nil
+	case "status":
+		return optionalStatus(sel.GetBettingStatus()), nil
+	case "price":
+		return optionalDouble(sel.GetPrice()), nil
+	}
+	return nil, fmt.Errorf("selections have no field %q", name)
+}
+
+func optionalString(v *model.OptionalString) value {
+	if v == nil || v.GetDeleted() {
+		return nil
+	}
+	return v.GetValue()
+}
+
+func optionalDouble(v *model.OptionalDouble) value {
+	if v == nil || v.GetDeleted() {
+		return nil
+	}
+	return v.GetValue()
+}
+
+func optionalInt64(v *model.OptionalInt64) value {
+	if v == nil || v.GetDeleted() {
+		return nil
+	}
+	return float64(v.GetValue())
+}
+
+func optionalStatus(v *model.OptionalBettingStatus) value {
+	if v == nil || v.GetDeleted() {
+		return model.BettingStatus_BettingUnknown.String()
+	}
+	return v.GetValue().String()
+}
+
+type literalNode struct {
+	value value
+}
+
+func (n *literalNode) eval(_ *scope) (value, error) {
+	return n.value, nil
+}
+
+// pathNode is a field, optionally prefixed by the enclosing level it belongs to e.g event.name from a market
+type pathNode struct {
+	parts []string
+}
+
+func (n *pathNode) eval(s *scope) (value, error) {
+	current := s
+	for _, parent := range n.parts[:len(n.parts)-1] {
+		switch parent {
+		case "event":
+			current = &scope{event: current.event}
+		case "market":
+			if current.market == nil {
+				return nil, fmt.Errorf("market is not available here")
+			}
+			current = &scope{event: current.event, market: current.market}
+		case "selection":
+			if current.selection == nil {
+				return nil, fmt.Errorf("selection is not available here")
+			}
+		}
+	}
+	return current.field(n.parts[len(n.parts)-1])
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(s *scope) (value, error) {
+	v, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! needs a condition, got %v", v)
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(s *scope) (value, error) {
+	left, err := truthy(n.left, s)
+	if err != nil {
+		return nil, err
+	}
+	// short circuit so conditions can guard each other
+	if (n.op == "&&" && !left) || (n.op == "||" && left) {
+		return left, nil
+	}
+	return truthy(n.right, s)
+}
+
+func truthy(n node, s *scope) (bool, error) {
+	v, err := n.eval(s)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a condition, got %v", v)
+	}
+	return b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+// eval compares two values of the same type. Missing values are only equal to each other, any ordering involving
+// a missing value is false so rules never fire on absent prices.
+func (n *compareNode) eval(s *scope) (value, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if left == nil || right == nil {
+		switch n.op {
+		case "==":
+			return left == nil && right == nil, nil
+		case "!=":
+			return (left == nil) != (right == nil), nil
+		default:
+			return false, nil
+		}
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %v with %v", left, right)
+		}
+		return compareOrdered(n.op, l, r), nil
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %v with %v", left, right)
+		}
+		return compareOrdered(n.op, l, r), nil
+	case bool:
+		r, ok := right.(bool)
+		if !ok || (n.op != "==" && n.op != "!=") {
+			return nil, fmt.Errorf("cannot compare %v with %v using %v", left, right, n.op)
+		}
+		return (l == r) == (n.op == "=="), nil
+	}
+	return nil, fmt.Errorf("cannot compare %v with %v", left, right)
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+// callNode is one of the functions over a list: any, all and count
+type callNode struct {
+	fn        string
+	list      *pathNode
+	predicate node
+}
+
+func (n *callNode) eval(s *scope) (value, error) {
+	v, err := n.list.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]*scope)
+	if !ok {
+		return nil, fmt.Errorf("%v needs markets or selections", n.fn)
+	}
+
+	matches := 0
+	for _, item := range list {
+		matched := true
+		if n.predicate != nil {
+			if matched, err = truthy(n.predicate, item); err != nil {
+				return nil, err
+			}
+		}
+		if matched {
+			matches++
+		}
+		if n.fn == "any" && matched {
+			return true, nil
+		}
+		if n.fn == "all" && !matched {
+			return false, nil
+		}
+	}
+
+	switch n.fn {
+	case "any":
+		return false, nil
+	case "all":
+		return true, nil
+	default:
+		return float64(matches), nil
+	}
+}
@@ -0,0 +1,286 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a token of an expression
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are the operators of the language, longest first so they are matched greedily
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", ",", "."}
+
+// lex splits an expression into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		r := rune(src[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], pos: start})
+		case r == '"':
+			start := i
+			i++
+			for i < len(src) && src[i] != '"' {
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: src[start+1 : i-1], pos: start})
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// parser is a recursive descent parser of the grammar
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = primary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) primary ]
+//	primary = number | string | "true" | "false" | status | call | path | "(" or ")"
+//	call    = ( "any" | "all" | "count" ) "(" path [ "," or ] ")"
+//	path    = ident { "." ident }
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse compiles an expression into an evaluable node
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the given operator
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at %d", op, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.compare()
+}
+
+func (p *parser) compare() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			return &compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", tok.text, tok.pos)
+		}
+		return &literalNode{value: f}, nil
+	case tokenString:
+		return &literalNode{value: tok.text}, nil
+	case tokenOperator:
+		if tok.text != "(" {
+			return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+		}
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case tokenIdent:
+		return p.ident(tok)
+	default:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+}
+
+// ident parses the expressions starting with an identifier: keywords, statuses, calls and paths
+func (p *parser) ident(tok token) (node, error) {
+	switch tok.text {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	}
+	if isStatus(tok.text) {
+		return &literalNode{value: tok.text}, nil
+	}
+
+	if p.accept("(") {
+		if !isFunction(tok.text) {
+			return nil, fmt.Errorf("unknown function %q at %d", tok.text, tok.pos)
+		}
+		list, err := p.path(p.next())
+		if err != nil {
+			return nil, err
+		}
+		call := &callNode{fn: tok.text, list: list}
+		if p.accept(",") {
+			if call.predicate, err = p.or(); err != nil {
+				return nil, err
+			}
+		} else if tok.text != "count" {
+			return nil, fmt.Errorf("%v needs a condition at %d", tok.text, tok.pos)
+		}
+		return call, p.expect(")")
+	}
+
+	return p.path(tok)
+}
+
+func (p *parser) path(tok token) (*pathNode, error) {
+	if tok.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a field at %d", tok.pos)
+	}
+
+	n := &pathNode{parts: []string{tok.text}}
+	for p.accept(".") {
+		part := p.next()
+		if part.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a field at %d", part.pos)
+		}
+		n.parts = append(n.parts, part.text)
+	}
+
+	field := n.parts[len(n.parts)-1]
+	if !isField(field) {
+		return nil, fmt.Errorf("unknown field %q at %d", field, tok.pos)
+	}
+	for _, parent := range n.parts[:len(n.parts)-1] {
+		if !isParent(parent) {
+			return nil, fmt.Errorf("unknown scope %q at %d", parent, tok.pos)
+		}
+	}
+
+	return n, nil
+}
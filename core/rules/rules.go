@@ -0,0 +1,117 @@
+// Package rules implements declarative trading rules, written in a small expression language over model.Event fields.
+//
+// A rule applies to every event, market or selection of an update (its scope) for which its condition holds, e.g
+//
+//	name: suspend-line-when-h2h-short
+//	scope: market
+//	when: name == "Line" && any(event.markets, name == "Head to Head" && any(selections, price < 1.05))
+//	set:
+//	  bettingStatus: BettingSuspended
+//
+// Conditions compare fields with ==, !=, <, <=, > and >=, combine them with &&, || and !, and look into markets and
+// selections with any(list, condition), all(list, condition) and count(list[, condition]). Fields are resolved on the
+// current scope, event. and market. reach the enclosing event and market.
+//
+// The status a rule sets is recorded along with the rule (StatusRule and StatusBeforeRule of the model), and the status
+// it replaced is restored once its condition no longer holds, unless the status was changed meanwhile.
+package rules
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Scopes a rule can apply to
+const (
+	ScopeEvent     = "event"
+	ScopeMarket    = "market"
+	ScopeSelection = "selection"
+)
+
+// Action is what a rule changes on the parts of an event it applies to
+type Action struct {
+	BettingStatus string `yaml:"bettingStatus"`
+}
+
+// Rule is a condition over a scope of an event and the action taken where it holds
+type Rule struct {
+	Name  string `yaml:"name"`
+	Scope string `yaml:"scope"`
+	When  string `yaml:"when"`
+	Set   Action `yaml:"set"`
+
+	condition node
+	status    model.BettingStatus
+}
+
+// file is the layout of a rules file
+type file struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Load reads and compiles the rules of a YAML file
+func Load(path string) ([]*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed_to_read_rules: %w", err)
+	}
+
+	f := &file{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed_to_parse_rules: %w", err)
+	}
+
+	for _, rule := range f.Rules {
+		if err := rule.Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.Rules, nil
+}
+
+// Compile parses the condition of the rule and validates it against the scope, along with the action. It must be
+// called before Matches.
+func (r *Rule) Compile() error {
+	switch r.Scope {
+	case ScopeEvent, ScopeMarket, ScopeSelection:
+	default:
+		return fmt.Errorf("rule %q has unknown scope %q", r.Name, r.Scope)
+	}
+
+	status, ok := model.BettingStatus_value[r.Set.BettingStatus]
+	if !ok || model.BettingStatus(status) == model.BettingStatus_BettingUnknown {
+		return fmt.Errorf("rule %q sets unknown betting status %q", r.Name, r.Set.BettingStatus)
+	}
+	r.status = model.BettingStatus(status)
+
+	condition, err := parse(r.When)
+	if err != nil {
+		return fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+	if err := checkCondition(condition, scopeOf(r.Scope)); err != nil {
+		return fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+	r.condition = condition
+
+	return nil
+}
+
+// Status returns the betting status the rule sets
+func (r *Rule) Status() model.BettingStatus {
+	return r.status
+}
+
+// Matches evaluates the condition of the rule on an event, market or selection. The market and selection are nil
+// for rules scoped to events, and the selection is nil for rules scoped to markets.
+func (r *Rule) Matches(event *model.Event, market *model.Market, selection *model.Selection) (bool, error) {
+	matched, err := truthy(r.condition, &scope{event: event, market: market, selection: selection})
+	if err != nil {
+		return false, fmt.Errorf("rule %q: %w", r.Name, err)
+	}
+	return matched, nil
+}
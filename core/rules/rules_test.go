@@ -0,0 +1,147 @@
+package rules_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/rules"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func selection(id string, status model.BettingStatus, price float64) *model.Selection {
+	return &model.Selection{
+		ID:            id,
+		BettingStatus: &model.OptionalBettingStatus{Value: status},
+		Price:         &model.OptionalDouble{Value: price},
+	}
+}
+
+func testEvent() *model.Event {
+	return &model.Event{
+		ID:          "evt-1",
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+		Markets: []*model.Market{
+			{
+				ID:   "h2h",
+				Name: &model.OptionalString{Value: "Head to Head"},
+				Selections: []*model.Selection{
+					selection("home", model.BettingStatus_BettingOpen, 1.04),
+					selection("away", model.BettingStatus_BettingOpen, 12),
+				},
+			},
+			{
+				ID:         "line",
+				Name:       &model.OptionalString{Value: "Line"},
+				Selections: []*model.Selection{selection("home", model.BettingStatus_BettingSuspended, 1.9)},
+			},
+			{ID: "empty", Name: &model.OptionalString{Value: "Totals"}},
+		},
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	event := testEvent()
+	cases := []struct {
+		when      string
+		market    int
+		selection int
+		want      bool
+	}{
+		{`name == "Line" && any(event.markets, name == "Head to Head" && any(selections, price < 1.05))`, 1, -1, true},
+		{`name == "Line" && any(event.markets, name == "Head to Head" && any(selections, price < 1.05))`, 0, -1, false},
+		{`count(selections, status == BettingOpen) == 0`, 1, -1, true},
+		{`count(selections, status == BettingOpen) == 0`, 0, -1, false},
+		{`count(selections) == 0 && event.eventTypeID == "soccer"`, 2, -1, true},
+		{`all(selections, price > 1.01) || !(id != "h2h")`, 0, -1, true},
+		{`price >= 12 && market.name == "Head to Head"`, 0, 1, true},
+		{`overround > 1`, 0, -1, false}, // missing values never match an ordering
+		{`overround == overround`, 0, -1, true},
+	}
+
+	for _, c := range cases {
+		scope := rules.ScopeMarket
+		if c.selection >= 0 {
+			scope = rules.ScopeSelection
+		}
+		rule := &rules.Rule{Name: "test", Scope: scope, When: c.when, Set: rules.Action{
+			BettingStatus: "BettingSuspended",
+		}}
+		if err := rule.Compile(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", c.when, err)
+		}
+
+		market := event.Markets[c.market]
+		var sel *model.Selection
+		if c.selection >= 0 {
+			sel = market.Selections[c.selection]
+		}
+		got, err := rule.Matches(event, market, sel)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", c.when, err)
+		}
+		if got != c.want {
+			t.Fatalf("%v: expected %v, got %v", c.when, c.want, got)
+		}
+	}
+}
+
+func TestRule_CompileErrors(t *testing.T) {
+	invalid := []string{
+		`name == `,
+		`name = "Line"`,
+		`colour == "red"`,
+		`any(selections)`,
+		`sum(selections, price)`,
+		`(name == "Line"`,
+		`name == "Line`,
+		`price < 1.05`,                  // a selection field in a market rule
+		`selection.name == "Home"`,      // no selection to look into
+		`any(event.markets, price < 2)`, // markets have no price
+		`name == 1`,
+		`status > true`,
+		`count(selections)`,
+		`any(name, true)`,
+		`!overround`,
+	}
+
+	for _, when := range invalid {
+		rule := &rules.Rule{Name: "test", Scope: rules.ScopeMarket, When: when, Set: rules.Action{
+			BettingStatus: "BettingSuspended",
+		}}
+		if err := rule.Compile(); err == nil {
+			t.Fatalf("%v: expected a compile error", when)
+		}
+	}
+
+	rule := &rules.Rule{Name: "test", Scope: "league", When: "true", Set: rules.Action{BettingStatus: "BettingClosed"}}
+	if err := rule.Compile(); err == nil {
+		t.Fatalf("expected an error for an unknown scope")
+	}
+	rule = &rules.Rule{Name: "test", Scope: rules.ScopeEvent, When: "true", Set: rules.Action{BettingStatus: "Hidden"}}
+	if err := rule.Compile(); err == nil {
+		t.Fatalf("expected an error for an unknown betting status")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	def := `rules:
+  - name: close-empty-markets
+    scope: market
+    when: count(selections) == 0
+    set:
+      bettingStatus: BettingClosed
+`
+	if err := os.WriteFile(path, []byte(def), 0o600); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	loaded, err := rules.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Status() != model.BettingStatus_BettingClosed {
+		t.Fatalf("unexpected rules %+v", loaded)
+	}
+}
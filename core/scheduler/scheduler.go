@@ -0,0 +1,218 @@
+// Package scheduler suspends or closes betting on events when they start
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Updater runs an update through the Update pipeline, it is implemented by service.Service
+type Updater interface {
+	Update(ctx context.Context, req *core.UpdateRequest) (*core.UpdateResponse, error)
+}
+
+// Config holds when and how betting is stopped on events
+type Config struct {
+	Status          model.BettingStatus      // status applied at start time, defaults to BettingSuspended
+	Offsets         map[string]time.Duration // offset from StartTime by EventTypeID, negative offsets act before the jump
+	DefaultOffset   time.Duration            // offset for event types without an entry in Offsets
+	RefreshInterval time.Duration            // how often the schedule is rebuilt from storage, defaults to 30s
+	Resolution      time.Duration            // how often due events are checked, defaults to 1s
+	Horizon         time.Duration            // how far ahead events are scheduled, defaults to an hour
+	Lookback        time.Duration            // how long missed start times are still acted on, defaults to an hour
+	Now             func() time.Time         // defaults to time.Now
+}
+
+// Scheduler tracks upcoming event start times and stops betting on them at the jump. The schedule is rebuilt from
+// storage periodically so it survives restarts, and events remember the start time they were actioned for so an event
+// that is reopened after the jump, e.g for in play betting, is not suspended again.
+type Scheduler struct {
+	config   Config
+	repo     repository.Repository
+	updater  Updater
+	mtx      sync.Mutex
+	schedule map[string]entry
+}
+
+// entry is an event waiting for its start time
+type entry struct {
+	startTime int64
+	due       time.Time
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(repo repository.Repository, updater Updater, config Config) *Scheduler {
+	if config.Status == model.BettingStatus_BettingUnknown {
+		config.Status = model.BettingStatus_BettingSuspended
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = 30 * time.Second
+	}
+	if config.Resolution <= 0 {
+		config.Resolution = time.Second
+	}
+	if config.Horizon <= 0 {
+		config.Horizon = time.Hour
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = time.Hour
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &Scheduler{config: config, repo: repo, updater: updater, schedule: map[string]entry{}}
+}
+
+// Run keeps the schedule up to date and acts on due events until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	logrus.Info("scheduler_starting")
+	defer logrus.Info("scheduler_stopped")
+
+	if err := s.Refresh(ctx); err != nil {
+		logrus.WithError(err).Error("scheduler_refresh_failed")
+	}
+
+	refresh := time.NewTicker(s.config.RefreshInterval)
+	defer refresh.Stop()
+	resolution := time.NewTicker(s.config.Resolution)
+	defer resolution.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			if err := s.Refresh(ctx); err != nil {
+				logrus.WithError(err).Error("scheduler_refresh_failed")
+			}
+		case <-resolution.C:
+			s.Fire(ctx)
+		}
+	}
+}
+
+// Refresh rebuilds the schedule from the events stored in the repository
+func (s *Scheduler) Refresh(ctx context.Context) error {
+	minOffset, maxOffset := s.offsetBounds()
+	now := s.config.Now()
+	ids, err := s.repo.GetEventIDsByStartTime(ctx, now.Add(-s.config.Lookback-maxOffset),
+		now.Add(s.config.Horizon-minOffset))
+	if err != nil {
+		return err
+	}
+
+	schedule := make(map[string]entry, len(ids))
+	for _, id := range ids {
+		event, err := s.repo.GetEventByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if event == nil || !s.pending(event) {
+			continue
+		}
+		startTime := event.GetStartTime().GetValue()
+		schedule[id] = entry{
+			startTime: startTime,
+			due:       time.Unix(0, startTime).Add(s.offset(event.GetEventTypeID().GetValue())),
+		}
+	}
+
+	s.mtx.Lock()
+	s.schedule = schedule
+	s.mtx.Unlock()
+
+	logrus.WithField("events", len(schedule)).Debug("scheduler_refreshed")
+	return nil
+}
+
+// Fire runs a synthetic update for every event whose start time has been reached
+func (s *Scheduler) Fire(ctx context.Context) {
+	now := s.config.Now()
+	due := map[string]entry{}
+
+	s.mtx.Lock()
+	for id, e := range s.schedule {
+		if !e.due.After(now) {
+			due[id] = e
+			delete(s.schedule, id)
+		}
+	}
+	s.mtx.Unlock()
+
+	for id, e := range due {
+		// synthetic updates get a request ID of their own, like the updates sent by clients
+		updateCtx := logging.WithFields(ctx, logrus.Fields{
+			logging.FieldRequestID: logging.NewRequestID(),
+			logging.FieldMethod:    "scheduler",
+			logging.FieldEventID:   id,
+		})
+		logger := logging.FromContext(updateCtx).WithField("status", s.config.Status.String())
+
+		// the event may have been deleted, actioned or moved since the schedule was built, a synthetic update for a
+		// deleted event would store an event holding nothing but its betting status
+		event, err := s.repo.GetEventByID(updateCtx, id)
+		if err != nil {
+			logger.WithError(err).Error("scheduler_update_failed")
+			continue
+		}
+		if event == nil || !s.pending(event) || event.GetStartTime().GetValue() != e.startTime {
+			logger.Debug("scheduler_event_changed")
+			continue
+		}
+
+		_, err = s.updater.Update(updateCtx, &core.UpdateRequest{
+			Event: &model.Event{
+				ID:            id,
+				BettingStatus: &model.OptionalBettingStatus{Value: s.config.Status},
+				StartActioned: &model.OptionalInt64{Value: e.startTime},
+			},
+		})
+		if err != nil {
+			// the event is still pending in storage so the next refresh will schedule it again
+			logger.WithError(err).Error("scheduler_update_failed")
+			continue
+		}
+		logger.Info("scheduler_stopped_betting")
+	}
+}
+
+// pending reports whether the event still needs betting to be stopped for its current start time
+func (s *Scheduler) pending(event *model.Event) bool {
+	startTime := event.GetStartTime()
+	if startTime == nil || startTime.GetDeleted() {
+		return false
+	}
+	if actioned := event.GetStartActioned(); actioned != nil && actioned.GetValue() == startTime.GetValue() {
+		return false // already actioned, a delayed start moves StartTime and schedules the event again
+	}
+
+	status := event.GetBettingStatus().GetValue()
+	return status != model.BettingStatus_BettingClosed && status != s.config.Status
+}
+
+// offset returns the offset from StartTime at which betting stops for an event type
+func (s *Scheduler) offset(eventTypeID string) time.Duration {
+	if offset, ok := s.config.Offsets[eventTypeID]; ok {
+		return offset
+	}
+	return s.config.DefaultOffset
+}
+
+// offsetBounds returns the smallest and largest configured offsets
+func (s *Scheduler) offsetBounds() (time.Duration, time.Duration) {
+	minOffset, maxOffset := s.config.DefaultOffset, s.config.DefaultOffset
+	for _, offset := range s.config.Offsets {
+		minOffset = min(minOffset, offset)
+		maxOffset = max(maxOffset, offset)
+	}
+	return minOffset, maxOffset
+}
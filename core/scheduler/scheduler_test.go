@@ -0,0 +1,125 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository/mock"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/scheduler"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+type recordingUpdater struct {
+	requests []*core.UpdateRequest
+}
+
+func (u *recordingUpdater) Update(_ context.Context, req *core.UpdateRequest) (*core.UpdateResponse, error) {
+	u.requests = append(u.requests, req)
+	return &core.UpdateResponse{Message: "Success"}, nil
+}
+
+func TestScheduler_SuspendsAtStartTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1758244443, 0)
+	clock := func() time.Time { return now }
+	repo := mock.NewMockRepository(ctrl)
+	updater := &recordingUpdater{}
+	sched := scheduler.NewScheduler(repo, updater, scheduler.Config{
+		Offsets: map[string]time.Duration{"horse_racing": -time.Minute},
+		Now:     clock,
+	})
+
+	events := map[string]*model.Event{
+		"soccer-1": {
+			ID:          "soccer-1",
+			EventTypeID: &model.OptionalString{Value: "soccer"},
+			StartTime:   &model.OptionalInt64{Value: now.Add(30 * time.Second).UnixNano()},
+		},
+		"racing-1": {
+			ID:          "racing-1",
+			EventTypeID: &model.OptionalString{Value: "horse_racing"},
+			StartTime:   &model.OptionalInt64{Value: now.Add(30 * time.Second).UnixNano()},
+		},
+		"actioned-1": {
+			ID:            "actioned-1",
+			StartTime:     &model.OptionalInt64{Value: now.Add(-time.Minute).UnixNano()},
+			StartActioned: &model.OptionalInt64{Value: now.Add(-time.Minute).UnixNano()},
+			BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+		},
+	}
+
+	repo.EXPECT().GetEventIDsByStartTime(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]string{"soccer-1", "racing-1", "actioned-1"}, nil)
+	repo.EXPECT().GetEventByID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string) (*model.Event, error) {
+			return events[id], nil
+		}).Times(5) // once on refresh, again before firing
+
+	if err := sched.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// racing is due a minute before the jump, soccer at the jump
+	sched.Fire(context.Background())
+	if len(updater.requests) != 1 || updater.requests[0].GetEvent().GetID() != "racing-1" {
+		t.Fatalf("expected racing-1 to be suspended, got %v", updater.requests)
+	}
+	req := updater.requests[0].GetEvent()
+	if req.GetBettingStatus().GetValue() != model.BettingStatus_BettingSuspended {
+		t.Fatalf("expected status %v, got %v", model.BettingStatus_BettingSuspended, req.GetBettingStatus().GetValue())
+	}
+	if req.GetStartActioned().GetValue() != events["racing-1"].GetStartTime().GetValue() {
+		t.Fatalf("expected start time to be marked as actioned, got %v", req.GetStartActioned())
+	}
+
+	now = now.Add(30 * time.Second)
+	sched.Fire(context.Background())
+	if len(updater.requests) != 2 || updater.requests[1].GetEvent().GetID() != "soccer-1" {
+		t.Fatalf("expected soccer-1 to be suspended, got %v", updater.requests)
+	}
+
+	// nothing fires twice
+	sched.Fire(context.Background())
+	if len(updater.requests) != 2 {
+		t.Fatalf("expected no more updates, got %v", updater.requests)
+	}
+}
+
+func TestScheduler_SkipsDeletedEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1758244443, 0)
+	repo := mock.NewMockRepository(ctrl)
+	updater := &recordingUpdater{}
+	sched := scheduler.NewScheduler(repo, updater, scheduler.Config{Now: func() time.Time { return now }})
+
+	events := map[string]*model.Event{
+		"soccer-1": {
+			ID:        "soccer-1",
+			StartTime: &model.OptionalInt64{Value: now.UnixNano()},
+		},
+	}
+	repo.EXPECT().GetEventIDsByStartTime(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"soccer-1"}, nil)
+	repo.EXPECT().GetEventByID(gomock.Any(), "soccer-1").DoAndReturn(
+		func(_ context.Context, id string) (*model.Event, error) {
+			return events[id], nil
+		}).Times(2)
+
+	if err := sched.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// deleted after the schedule was built
+	delete(events, "soccer-1")
+	sched.Fire(context.Background())
+	if len(updater.requests) != 0 {
+		t.Fatalf("expected no update for a deleted event, got %v", updater.requests)
+	}
+}
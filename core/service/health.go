@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+)
+
+// readinessTimeout bounds the repository health check made by /readyz
+const readinessTimeout = 2 * time.Second
+
+// registerHealthServer registers the grpc.health.v1 Health service, it reports not serving until the service is ready
+func (host *Service) registerHealthServer(grpcServer *grpc.Server) {
+	host.healthServer = health.NewServer()
+	host.setReady(false)
+	healthpb.RegisterHealthServer(grpcServer, host.healthServer)
+}
+
+// setReady marks the service as ready, or not, to take requests
+func (host *Service) setReady(ready bool) {
+	host.ready.Store(ready)
+
+	if host.healthServer == nil {
+		return
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	host.healthServer.SetServingStatus("", status)
+	host.healthServer.SetServingStatus(core.Service_ServiceDesc.ServiceName, status)
+}
+
+// Ready reports whether the gRPC server is serving and the service is not stopping
+func (host *Service) Ready() bool {
+	return host.ready.Load()
+}
+
+// registerHealthRoutes adds the liveness and readiness endpoints to the router
+func (host *Service) registerHealthRoutes(router *mux.Router) {
+	router.HandleFunc("/healthz", host.handleLiveness).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", host.handleReadiness).Methods(http.MethodGet)
+}
+
+// handleLiveness reports that the process is up
+func (host *Service) handleLiveness(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, http.StatusOK, "ok")
+}
+
+// handleReadiness reports whether the service can take requests, which requires a healthy repository
+func (host *Service) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	if !host.Ready() {
+		writeHealth(w, http.StatusServiceUnavailable, "not_ready: service is not serving")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if !host.Upstreams.Repo.HealthCheck(ctx) {
+		writeHealth(w, http.StatusServiceUnavailable, "not_ready: repository is unhealthy")
+		return
+	}
+
+	writeHealth(w, http.StatusOK, "ready")
+}
+
+func writeHealth(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	if _, err := w.Write([]byte(message + "\n")); err != nil {
+		logrus.WithError(err).Debug("health_write_failed")
+	}
+}
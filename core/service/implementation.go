@@ -3,22 +3,36 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"sync"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
 
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/changes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
 // Upstreams defines dependencies the service has on other services
 type Upstreams struct {
 	MergerClient merger.ServiceClient
 	Repo         repository.Repository
-	Transforms   []transforms.TransformClient
+	Transforms   []transforms.TransformClient // run in order when no Pipeline is set
+	Pipeline     *transforms.Pipeline
+	SportTypes   *sporttypes.Registry // mapping used by the sport transform, managed through the admin RPCs
+	Changes      *changes.Broker      // notified of the changes made by every successful update, when set
 }
 
 // NewService creates a new instance of Service
@@ -33,48 +47,184 @@ func (host *Service) RegisterGRPCServerImplementations(grpcServer *grpc.Server)
 
 // Update updates an Event and runs the pipeline of transformations
 func (host *Service) Update(ctx context.Context, req *core.UpdateRequest) (*core.UpdateResponse, error) {
+	if host.UpdateTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, host.UpdateTimeout)
+		defer cancel()
+	}
+
+	// a newer update stored while this one was transformed is seen as such on the next attempt
+	for attempt := 1; ; attempt++ {
+		resp, err := host.update(ctx, req)
+		if !errors.Is(err, repository.ErrStaleSequence) {
+			return resp, err
+		}
+		if attempt == maxStaleAttempts {
+			logging.FromContext(ctx).WithError(err).Error("Update: newer updates kept being stored")
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+	}
+}
+
+// update applies an update to the event as currently stored
+func (host *Service) update(ctx context.Context, req *core.UpdateRequest) (*core.UpdateResponse, error) {
 	existing, err := host.Upstreams.Repo.GetEventByID(ctx, req.GetEvent().GetID())
 	if err != nil {
-		logrus.WithError(err).Error("Update: failed to retrieve event")
+		logging.FromContext(ctx).WithError(err).Error("Update: failed to retrieve event")
 		return nil, err
 	}
 
 	resp := &core.UpdateResponse{Message: "Success"}
 
 	update := req.GetEvent()
-	if existing == nil {
+	kind := metrics.UpdateUpdated
+	switch {
+	case existing == nil:
+		// the sequence is set on the update below, which must not change the request
+		update = proto.Clone(req.GetEvent()).(*model.Event)
+		kind = metrics.UpdateCreated
 		resp.Message = fmt.Sprintf("New Event born %v", req.GetEvent().GetID())
-	} else {
-		update, err = host.Upstreams.MergerClient.MergeEvent(context.Background(), existing, req.GetEvent())
+	case isDuplicate(existing, req):
+		// a redelivered update has already been applied, running it again would record its changes twice
+		logging.FromContext(ctx).WithField("sequence", req.GetSequence()).
+			Infof("Update: ignored duplicate update for event %v", req.GetEvent().GetID())
+		metrics.EventUpdated(metrics.UpdateDuplicate)
+		resp.Message = fmt.Sprintf("Duplicate update ignored %v", req.GetEvent().GetID())
+		return resp, nil
+	case isStale(existing, req):
+		resp.Stale = true
+		if host.StaleUpdatePolicy == StaleUpdateDrop {
+			logging.FromContext(ctx).WithField("sequence", req.GetSequence()).
+				WithField("lastSequence", existing.GetSequence().GetValue()).
+				Warnf("Update: dropped stale update for event %v", req.GetEvent().GetID())
+			metrics.EventUpdated(metrics.UpdateStaleDropped)
+			resp.Message = fmt.Sprintf("Stale update dropped %v", req.GetEvent().GetID())
+			return resp, nil
+		}
+		kind = metrics.UpdateStalePartial
+
+		// merge the other way around so values that have already been applied win over the stale ones
+		update, err = host.Upstreams.MergerClient.MergeEvent(ctx, req.GetEvent(), existing)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Update: failed to merge stale event")
+			return nil, err
+		}
+		resp.Message = fmt.Sprintf("Stale update partially applied %v", req.GetEvent().GetID())
+	default:
+		update, err = host.Upstreams.MergerClient.MergeEvent(ctx, existing, req.GetEvent())
 		if err != nil {
-			logrus.WithError(err).Error("Update: failed to merge event")
+			logging.FromContext(ctx).WithError(err).Error("Update: failed to merge event")
 			return nil, err
 		}
 	}
 
-	for _, t := range host.Upstreams.Transforms {
-		upd, tErr := t.TransformEvent(ctx, req.Event, update)
-		if tErr != nil {
-			logrus.WithError(tErr).Errorf("Update: failed to run transform %v", t.GetName())
-		}
-		if upd != nil {
-			update, err = host.Upstreams.MergerClient.MergeEvent(context.Background(), update, upd)
-			if err != nil {
-				logrus.WithError(err).Errorf("Update: failed to merge event in transform %v", t.GetName())
-				return nil, err
-			}
-		}
+	if !resp.Stale && req.GetSequence() != 0 {
+		update.Sequence = &model.OptionalInt64{Value: req.GetSequence()}
 	}
 
-	err = host.Upstreams.Repo.UpdateEvent(ctx, update)
+	update, outputs, failures, err := host.pipeline().RunWithOutputs(ctx, host.Upstreams.MergerClient, req.GetEvent(),
+		update)
+	for _, failure := range failures {
+		resp.TransformFailures = append(resp.TransformFailures, &core.TransformFailure{
+			Transform: failure.Transform,
+			Error:     failure.Err.Error(),
+		})
+	}
 	if err != nil {
-		logrus.WithError(err).Error("Update: failed to update event")
+		logging.FromContext(ctx).WithError(err).Error("Update: failed to run transforms")
+		return nil, transformError(err, resp.TransformFailures)
+	}
+
+	// stored and published under the lock of the event, so subscribers see its changes in the order they were stored
+	lock := host.eventLock(update.GetID())
+	lock.Lock()
+	defer lock.Unlock()
+
+	err = host.Upstreams.Repo.UpdateEvent(ctx, update)
+	if errors.Is(err, repository.ErrStaleSequence) {
+		return nil, err
+	} else if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Update: failed to update event")
 		return nil, err
 	}
+	metrics.EventUpdated(kind)
+	host.publishChange(ctx, req.GetEvent(), resp.Stale, update, outputs)
 
 	return resp, nil
 }
 
+// maxStaleAttempts is how many times an update is applied when newer updates keep being stored meanwhile
+const maxStaleAttempts = 3
+
+// transformError returns the error of an update failed by its transforms, carrying their failures as status details
+func transformError(err error, failures []*core.TransformFailure) error {
+	if len(failures) == 0 {
+		return err
+	}
+
+	st := status.New(codes.Internal, err.Error())
+	details := make([]protoadapt.MessageV1, 0, len(failures))
+	for _, failure := range failures {
+		details = append(details, failure)
+	}
+	if withDetails, detailsErr := st.WithDetails(details...); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// eventLock returns the lock serializing the updates of an event, events share a fixed number of locks by their ID
+func (host *Service) eventLock(id string) *sync.Mutex {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(id))
+	return &host.eventLocks[hash.Sum32()%uint32(len(host.eventLocks))]
+}
+
+// pipeline returns the pipeline of transforms run on every update
+func (host *Service) pipeline() *transforms.Pipeline {
+	if host.Upstreams.Pipeline != nil {
+		return host.Upstreams.Pipeline
+	}
+	return transforms.NewSequentialPipeline(host.Upstreams.Transforms...)
+}
+
+// publishChange notifies the subscribers to the event of the values changed by an update: the update itself along with
+// the outputs of the transforms. A stale update may have been partially applied, so the whole event is sent instead.
+func (host *Service) publishChange(ctx context.Context, partialUpdate *model.Event, stale bool, update *model.Event,
+	outputs []*model.Event,
+) {
+	if host.Upstreams.Changes == nil {
+		return
+	}
+
+	delta := update
+	if !stale {
+		delta = proto.Clone(partialUpdate).(*model.Event)
+		delta.Sequence = update.GetSequence()
+		for _, output := range outputs {
+			delta = merger.MergeEvent(ctx, delta, output)
+		}
+	}
+
+	host.Upstreams.Changes.Publish(&changes.Change{Event: update, Delta: delta})
+}
+
+// isStale reports whether the update is older than the last update applied to the existing event.
+// Updates without a sequence are never considered stale.
+func isStale(existing *model.Event, req *core.UpdateRequest) bool {
+	if req.GetSequence() == 0 || existing.GetSequence() == nil {
+		return false
+	}
+
+	return req.GetSequence() < existing.GetSequence().GetValue()
+}
+
+// isDuplicate reports whether the update carries the sequence of the last update applied to the existing event
+func isDuplicate(existing *model.Event, req *core.UpdateRequest) bool {
+	return req.GetSequence() != 0 && existing.GetSequence() != nil &&
+		req.GetSequence() == existing.GetSequence().GetValue()
+}
+
 // GetSportEvent retrieves a model.Event from the database and returns a core.SportEvent,
 // this is a more UserConsumable representation of the model that is specific to sport events
 func (host *Service) GetSportEvent(ctx context.Context, req *core.GetSportEventRequest) (
@@ -82,7 +232,7 @@ func (host *Service) GetSportEvent(ctx context.Context, req *core.GetSportEventR
 ) {
 	existing, err := host.Upstreams.Repo.GetEventByID(ctx, req.GetEventID())
 	if err != nil {
-		logrus.WithError(err).Error("GetSportEvent: failed to retrieve event")
+		logging.FromContext(ctx).WithError(err).Error("GetSportEvent: failed to retrieve event")
 		return nil, err
 	}
 
@@ -94,7 +244,54 @@ func (host *Service) GetSportEvent(ctx context.Context, req *core.GetSportEventR
 
 	rslt := &core.SportEvent{}
 	rslt.ConvertFromModel(existing)
+	rslt.ConvertPricesFromModel(existing, req.GetOddsFormat())
 	resp.Event = rslt
 
 	return resp, nil
 }
+
+// ListSportTypes lists the EventTypeID to sport mappings used by the sport transform
+func (host *Service) ListSportTypes(_ context.Context, _ *core.ListSportTypesRequest) (
+	*core.ListSportTypesResponse, error,
+) {
+	if host.Upstreams.SportTypes == nil {
+		return nil, status.Error(codes.FailedPrecondition, "sport types are not configured")
+	}
+
+	resp := &core.ListSportTypesResponse{}
+	for _, t := range host.Upstreams.SportTypes.List() {
+		resp.SportTypes = append(resp.SportTypes, &core.SportType{
+			EventTypeID: t.EventTypeID,
+			Name:        t.Name,
+			Region:      t.Region,
+			League:      t.League,
+		})
+	}
+
+	return resp, nil
+}
+
+// UpdateSportType adds or replaces an EventTypeID to sport mapping, events pick it up on their next update
+func (host *Service) UpdateSportType(ctx context.Context, req *core.UpdateSportTypeRequest) (
+	*core.UpdateSportTypeResponse, error,
+) {
+	if host.Upstreams.SportTypes == nil {
+		return nil, status.Error(codes.FailedPrecondition, "sport types are not configured")
+	}
+
+	t := sporttypes.SportType{
+		EventTypeID: req.GetSportType().GetEventTypeID(),
+		Name:        req.GetSportType().GetName(),
+		Region:      req.GetSportType().GetRegion(),
+		League:      req.GetSportType().GetLeague(),
+	}
+	if err := t.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := host.Upstreams.SportTypes.Set(t); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("UpdateSportType: failed to update sport type")
+		return nil, err
+	}
+
+	return &core.UpdateSportTypeResponse{SportType: req.GetSportType()}, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+)
+
+// maxRESTBodySize bounds the request bodies of the REST gateway, in line with the gRPC message size limit
+const maxRESTBodySize = 1024 * 1024 * 64
+
+// registerRESTRoutes adds the JSON gateway to the RPCs of the service. Bodies use the protojson encoding of the gRPC
+// messages, the same shape as the payloads in exampledata.
+func (host *Service) registerRESTRoutes(router *mux.Router) {
+	router.HandleFunc("/events/{id}", host.handleUpdate).Methods(http.MethodPut).
+		Name(core.Service_Update_FullMethodName)
+	router.HandleFunc("/sport-events/{id}", host.handleGetSportEvent).Methods(http.MethodGet).
+		Name(core.Service_GetSportEvent_FullMethodName)
+	router.HandleFunc("/sport-types", host.handleListSportTypes).Methods(http.MethodGet).
+		Name(core.Service_ListSportTypes_FullMethodName)
+	router.HandleFunc("/sport-types/{eventTypeID}", host.handleUpdateSportType).Methods(http.MethodPut).
+		Name(core.Service_UpdateSportType_FullMethodName)
+}
+
+// handleUpdate takes an UpdateRequest for the event of the path, the event ID of the body can be left out
+func (host *Service) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	req := &core.UpdateRequest{}
+	if !readREST(w, r, req) {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if req.GetEvent() == nil {
+		writeRESTError(w, status.Error(codes.InvalidArgument, "the request has no Event"))
+		return
+	}
+	if req.GetEvent().GetID() == "" {
+		req.GetEvent().ID = id
+	}
+	if req.GetEvent().GetID() != id {
+		writeRESTError(w, status.Errorf(codes.InvalidArgument,
+			"the event ID %q does not match the path", req.GetEvent().GetID()))
+		return
+	}
+
+	resp, err := host.Update(r.Context(), req)
+	writeREST(w, resp, err)
+}
+
+// handleGetSportEvent returns the sport event of the path, with prices in the oddsFormat query parameter if set
+func (host *Service) handleGetSportEvent(w http.ResponseWriter, r *http.Request) {
+	req := &core.GetSportEventRequest{EventID: mux.Vars(r)["id"]}
+	if format := r.URL.Query().Get("oddsFormat"); format != "" {
+		value, ok := core.OddsFormat_value[format]
+		if !ok {
+			writeRESTError(w, status.Errorf(codes.InvalidArgument, "unknown odds format %q", format))
+			return
+		}
+		req.OddsFormat = core.OddsFormat(value)
+	}
+
+	resp, err := host.GetSportEvent(r.Context(), req)
+	if err == nil && resp.GetEvent() == nil {
+		err = status.Errorf(codes.NotFound, "event %v not found", req.GetEventID())
+	}
+	writeREST(w, resp, err)
+}
+
+func (host *Service) handleListSportTypes(w http.ResponseWriter, r *http.Request) {
+	resp, err := host.ListSportTypes(r.Context(), &core.ListSportTypesRequest{})
+	writeREST(w, resp, err)
+}
+
+// handleUpdateSportType takes the SportType of the path as body, its EventTypeID can be left out
+func (host *Service) handleUpdateSportType(w http.ResponseWriter, r *http.Request) {
+	sportType := &core.SportType{}
+	if !readREST(w, r, sportType) {
+		return
+	}
+
+	eventTypeID := mux.Vars(r)["eventTypeID"]
+	if sportType.GetEventTypeID() == "" {
+		sportType.EventTypeID = eventTypeID
+	}
+	if sportType.GetEventTypeID() != eventTypeID {
+		writeRESTError(w, status.Errorf(codes.InvalidArgument,
+			"the event type %q does not match the path", sportType.GetEventTypeID()))
+		return
+	}
+
+	resp, err := host.UpdateSportType(r.Context(), &core.UpdateSportTypeRequest{SportType: sportType})
+	writeREST(w, resp, err)
+}
+
+// readREST decodes the protojson body of a request, writing an error response when it cannot
+func readREST(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRESTBodySize))
+	if err != nil {
+		writeRESTError(w, status.Errorf(codes.InvalidArgument, "failed to read body: %v", err))
+		return false
+	}
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		writeRESTError(w, status.Errorf(codes.InvalidArgument, "invalid body: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeREST writes the response of an RPC as protojson, or its error
+func writeREST(w http.ResponseWriter, resp proto.Message, err error) {
+	if err != nil {
+		writeRESTError(w, err)
+		return
+	}
+	writeRESTMessage(w, http.StatusOK, resp)
+}
+
+// writeRESTError writes an error as a google.rpc.Status with the HTTP status matching its gRPC code
+func writeRESTError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeRESTMessage(w, httpStatus(st.Code()), st.Proto())
+}
+
+func writeRESTMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		logrus.WithError(err).Error("rest_marshal_failed")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(data); err != nil {
+		logrus.WithError(err).Debug("rest_write_failed")
+	}
+}
+
+// httpStatus maps a gRPC status code to the HTTP status of the REST gateway
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,18 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 	recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/reflection"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/certs"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/metrics"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ratelimit"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/tracing"
+)
+
+// StaleUpdatePolicy controls what Update does with an update that is older than the last one applied
+type StaleUpdatePolicy int
+
+const (
+	// StaleUpdateDrop discards stale updates entirely
+	StaleUpdateDrop StaleUpdatePolicy = iota
+	// StaleUpdatePartial applies only the parts of a stale update that have not been set by a newer update
+	StaleUpdatePartial
 )
 
 // Service describes the main service of the application housing a grpc and http server
@@ -23,13 +44,20 @@ type Service struct {
 	httpServer        *http.Server
 	GRPCPort          int
 	HTTPPort          int
-	shutdownCallbacks []func() // Shutdown cleanup callbacks
+	StaleUpdatePolicy StaleUpdatePolicy
+	UpdateTimeout     time.Duration       // deadline for handling an Update, unbounded when zero
+	DrainDelay        time.Duration       // time between reporting not ready and stopping, for load balancers to drain
+	Auth              *auth.Authenticator // authenticates the callers of the gRPC and REST APIs, open when nil
+	TLS               *certs.Reloader     // certificates of both servers, which run plaintext when nil
+	RateLimiter       *ratelimit.Limiter  // limits the requests of every client and sheds load, unlimited when nil
+	healthServer      *health.Server
+	ready             atomic.Bool
+	eventLocks        [256]sync.Mutex // serialize storing and publishing the updates of an event
+	shutdownCallbacks []func()        // Shutdown cleanup callbacks
 }
 
 // Run executes the current service in a blocking fashion.
 func (host *Service) Run() error {
-	// defer tracing.EnsureGlobalTracer(wrapper.serviceName)()
-
 	// run the http server in non-blocking mode (we'll run the grpc server in blocking mode)
 	errHealth := host.runHTTPServer()
 	if errHealth != nil {
@@ -39,22 +67,45 @@ func (host *Service) Run() error {
 	logrus.Info("constructing_grpc_server")
 
 	opts := []recovery.Option{
-		recovery.WithRecoveryHandler(func(p interface{}) error {
-			logrus.WithField("panic", p).
+		recovery.WithRecoveryHandlerContext(func(ctx context.Context, p interface{}) error {
+			logging.FromContext(ctx).WithField("panic", p).
 				WithField("Stack", string(debug.Stack())).Error("request_panic_caught")
 			return fmt.Errorf("server_panic")
 		}),
 	}
 
+	// recovered first so a panic in any interceptor fails the request only, and again around the handler so its
+	// panics are still logged, traced and counted with the request
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		recovery.UnaryServerInterceptor(opts...),
+		tracing.UnaryServerInterceptor(),
+		metrics.UnaryServerInterceptor(),
+		logging.UnaryServerInterceptor(),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		recovery.StreamServerInterceptor(opts...),
+		tracing.StreamServerInterceptor(),
+		metrics.StreamServerInterceptor(),
+		logging.StreamServerInterceptor(),
+	}
+	if host.Auth != nil {
+		unaryInterceptors = append(unaryInterceptors, host.Auth.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, host.Auth.StreamServerInterceptor())
+	}
+	if host.RateLimiter != nil {
+		// after auth so clients are told apart by who they are rather than where they connect from
+		unaryInterceptors = append(unaryInterceptors, host.RateLimiter.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, host.RateLimiter.StreamServerInterceptor())
+	}
+
 	serverOptions := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(1024 * 1024 * 64),
 		grpc.MaxSendMsgSize(1024 * 1024 * 64),
-		grpc.ChainUnaryInterceptor(
-			recovery.UnaryServerInterceptor(opts...),
-		),
-		grpc.ChainStreamInterceptor(
-			recovery.StreamServerInterceptor(opts...),
-		),
+		grpc.ChainUnaryInterceptor(append(unaryInterceptors, recovery.UnaryServerInterceptor(opts...))...),
+		grpc.ChainStreamInterceptor(append(streamInterceptors, recovery.StreamServerInterceptor(opts...))...),
+	}
+	if host.TLS != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(host.TLS.TLSConfig())))
 	}
 
 	host.grpcServer = grpc.NewServer(serverOptions...)
@@ -62,6 +113,7 @@ func (host *Service) Run() error {
 	// the service needs to register it's grpc implementations
 	host.RegisterGRPCServerImplementations(host.grpcServer)
 
+	host.registerHealthServer(host.grpcServer)
 	reflection.Register(host.grpcServer)
 
 	serviceAddress := fmt.Sprintf(":%d", host.GRPCPort)
@@ -71,7 +123,7 @@ func (host *Service) Run() error {
 	}
 	host.shutdownCallbacks = append(host.shutdownCallbacks, func() {
 		errClose := lis.Close()
-		if errClose != nil {
+		if errClose != nil && !errors.Is(errClose, net.ErrClosed) { // the gRPC server closes it when it stops
 			logrus.WithError(errClose).Warn("error_closing_grpc_listener")
 		}
 	})
@@ -79,20 +131,67 @@ func (host *Service) Run() error {
 	logrus.WithField("address", serviceAddress).Info("service_start")
 	defer logrus.WithField("address", serviceAddress).Info("service_stopping")
 
+	host.setReady(true)
 	return host.grpcServer.Serve(lis)
 }
 
-// Stop the wrapper service servers
-func (host *Service) Stop(_ context.Context) error {
+// Stop the wrapper service servers gracefully: the service reports not ready, change streams are ended, then the
+// servers stop accepting requests and wait for the ones in flight to complete. Servers still busy when the context
+// expires are stopped hard, failing the requests they were handling.
+func (host *Service) Stop(ctx context.Context) error {
 	logrus.Info("service_stop_requested")
 	defer logrus.Info("service_stop_completed")
+
+	// report not ready first so load balancers stop sending requests before the servers go away
+	host.setReady(false)
+	if host.healthServer != nil {
+		host.healthServer.Shutdown()
+	}
+	if host.DrainDelay > 0 {
+		logrus.WithField("delay", host.DrainDelay).Info("service_draining")
+		select {
+		case <-time.After(host.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	// change streams never go idle, end them so they do not hold up the HTTP server
+	if host.Upstreams != nil && host.Upstreams.Changes != nil {
+		host.Upstreams.Changes.Close()
+	}
+
+	var wg sync.WaitGroup
+	var errs []error
+	var mtx sync.Mutex
+	fail := func(err error) {
+		mtx.Lock()
+		errs = append(errs, err)
+		mtx.Unlock()
+	}
+
 	if host.grpcServer != nil {
-		logrus.Info("service_grpc_stopping")
-		host.grpcServer.Stop()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !host.stopGRPCServer(ctx) {
+				fail(fmt.Errorf("grpc_server_stopped_hard: %w", ctx.Err()))
+			}
+		}()
 	} else {
 		logrus.Warn("grpc_service_shutdown_skipped")
 	}
 
+	if host.httpServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := host.stopHTTPServer(ctx); err != nil {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+
 	logrus.Info("service_stop_callbacks")
 	// Stop all shutdown callbacks
 	for i, callback := range host.shutdownCallbacks {
@@ -102,20 +201,59 @@ func (host *Service) Stop(_ context.Context) error {
 		logger.Debug("service_stop_callback_complete")
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// stopGRPCServer waits for the RPCs in flight to complete, then stops the gRPC server. The server is stopped hard
+// when the context expires first, in which case it returns false.
+func (host *Service) stopGRPCServer(ctx context.Context) bool {
+	logrus.Info("service_grpc_stopping")
+	stopped := make(chan struct{})
+	go func() {
+		host.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		logrus.Info("service_grpc_stopped")
+		return true
+	case <-ctx.Done():
+		logrus.WithError(ctx.Err()).Warn("service_grpc_stop_forced")
+		host.grpcServer.Stop()
+		<-stopped
+		return false
+	}
+}
+
+// stopHTTPServer waits for the HTTP requests in flight to complete, closing the server when the context expires first
+func (host *Service) stopHTTPServer(ctx context.Context) error {
+	logrus.Info("service_http_stopping")
+	err := host.httpServer.Shutdown(ctx)
+	if err == nil {
+		logrus.Info("service_http_stopped")
+		return nil
+	}
+
+	logrus.WithError(err).Warn("service_http_stop_forced")
+	if errClose := host.httpServer.Close(); errClose != nil {
+		logrus.WithError(errClose).Warn("error_closing_http_server")
+	}
+	return fmt.Errorf("http_server_stopped_hard: %w", err)
 }
 
 // runHealthEndpoint runs the health listener
 func (host *Service) runHTTPServer() error {
-	router := mux.NewRouter()
-
 	// Run our HTTP handler
 	address := fmt.Sprintf(":%v", host.HTTPPort)
 	srv := &http.Server{
 		Addr:              address,
-		Handler:           router,
+		Handler:           host.HTTPHandler(),
 		ReadHeaderTimeout: time.Second * 60,
 	}
+	if host.TLS != nil {
+		srv.TLSConfig = host.TLS.TLSConfig()
+	}
 
 	host.httpServer = srv
 	routine := sync.WaitGroup{}
@@ -129,8 +267,13 @@ func (host *Service) runHTTPServer() error {
 			"address": address,
 		}).Info("http_listener_starting")
 		routine.Done()
-		err := srv.ListenAndServe()
-		if err != nil {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "") // the certificates come from the TLS config
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			mtx.Lock()
 			errService = err
 			mtx.Unlock()
@@ -163,6 +306,26 @@ func (host *Service) runHTTPServer() error {
 	return errHealth
 }
 
+// HTTPHandler returns the handler of the HTTP server
+func (host *Service) HTTPHandler() http.Handler {
+	router := mux.NewRouter()
+	router.Use(logging.Middleware())
+	if host.Auth != nil {
+		// only the named routes of the API are authenticated, health and metrics stay open
+		router.Use(host.Auth.Middleware(writeRESTError))
+	}
+	host.registerHealthRoutes(router)
+	rest := router.NewRoute().Subrouter()
+	if host.RateLimiter != nil {
+		// the change streams are long-lived and are not limited, like the gRPC streams
+		rest.Use(host.RateLimiter.Middleware(writeRESTError))
+	}
+	host.registerRESTRoutes(rest)
+	host.registerStreamRoutes(router)
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	return router
+}
+
 // Retryable is a function that can be called over and over
 type Retryable func() error
 
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// streamKeepAlive is how often a comment is sent on idle streams so proxies do not close them
+const streamKeepAlive = 15 * time.Second
+
+// registerStreamRoutes adds the Server-Sent Events stream of event changes, readable by whoever may get the event
+func (host *Service) registerStreamRoutes(router *mux.Router) {
+	router.HandleFunc("/events/{id}/changes", host.handleEventChanges).Methods(http.MethodGet).
+		Name(core.Service_GetSportEvent_FullMethodName)
+}
+
+// handleEventChanges streams the changes of an event as Server-Sent Events: a snapshot event with the event as it is
+// stored, then a delta event for every successful update, with the values it changed. Both are model.Event in
+// protojson. The stream ends when the subscriber falls behind, clients reconnect to get a fresh snapshot.
+func (host *Service) handleEventChanges(w http.ResponseWriter, r *http.Request) {
+	if host.Upstreams.Changes == nil {
+		writeRESTError(w, status.Error(codes.FailedPrecondition, "change notifications are not configured"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeRESTError(w, status.Error(codes.Internal, "streaming is not supported"))
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	logger := logging.FromContext(r.Context()) // carries the event ID
+
+	// subscribe before reading the snapshot so no change is missed, changes already in the snapshot merge cleanly
+	sub := host.Upstreams.Changes.Subscribe(id)
+	defer sub.Close()
+
+	snapshot, err := host.Upstreams.Repo.GetEventByID(r.Context(), id)
+	if err != nil {
+		logger.WithError(err).Error("EventChanges: failed to retrieve event")
+		writeRESTError(w, err)
+		return
+	}
+	if snapshot == nil {
+		snapshot = &model.Event{ID: id}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeServerSentEvent(w, "snapshot", snapshot); err != nil {
+		logger.WithError(err).Debug("EventChanges: client gone")
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case change, ok := <-sub.C:
+			if !ok {
+				logger.Info("EventChanges: subscription closed")
+				return
+			}
+			if err := writeServerSentEvent(w, "delta", change.Delta); err != nil {
+				logger.WithError(err).Debug("EventChanges: client gone")
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeServerSentEvent writes a message as a Server-Sent Event, protojson output is always a single line
+func writeServerSentEvent(w http.ResponseWriter, event string, msg proto.Message) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -22,7 +22,7 @@ import (
 func setupIntegrationService(t *testing.T, eventID string) (*service.Service, repository.Repository, func()) {
 	t.Helper()
 
-	repo, err := repository.NewRedisRepository(context.Background(), "localhost:6379", "")
+	repo, err := repository.NewRedisRepository(context.Background(), "localhost:6379", "", repository.CompressionNone)
 	require.NoError(t, err)
 
 	host := &service.Service{
@@ -1,15 +1,35 @@
 package service_test
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/auth"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/changes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository/mock"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/service"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/laddertransform"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/sporttransform"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
@@ -89,6 +109,256 @@ func TestService_Update(t *testing.T) {
 	}
 }
 
+func TestService_UpdateStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+		},
+	}
+
+	ctx := context.Background()
+	existing := &model.Event{
+		ID:       "unit-stale-1",
+		Name:     &model.OptionalString{Value: "Current name"},
+		Sequence: &model.OptionalInt64{Value: 200},
+	}
+	stale := &model.Event{
+		ID:          existing.ID,
+		Name:        &model.OptionalString{Value: "Stale name"},
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+	}
+
+	// dropped by default, nothing is written
+	repo.EXPECT().GetEventByID(ctx, existing.ID).Return(existing, nil)
+	resp, err := host.Update(ctx, &core.UpdateRequest{Event: stale, Sequence: 100})
+	if err != nil {
+		t.Fatalf("unexpected error on stale update: %v", err)
+	}
+	if !resp.GetStale() {
+		t.Fatalf("expected stale update to be reported, got %+v", resp)
+	}
+
+	// partially applied, only fields that have not been set already are taken from the stale update
+	host.StaleUpdatePolicy = service.StaleUpdatePartial
+	// a redelivered update has already been applied, nothing is written whatever the policy
+	repo.EXPECT().GetEventByID(ctx, existing.ID).Return(existing, nil)
+	resp, err = host.Update(ctx, &core.UpdateRequest{Event: stale, Sequence: 200})
+	if err != nil {
+		t.Fatalf("unexpected error on duplicate update: %v", err)
+	}
+	if resp.GetStale() || !strings.HasPrefix(resp.GetMessage(), "Duplicate update ignored") {
+		t.Fatalf("expected duplicate update to be ignored, got %+v", resp)
+	}
+
+	repo.EXPECT().GetEventByID(ctx, existing.ID).Return(existing, nil)
+	repo.EXPECT().UpdateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, evt *model.Event) error {
+		if evt.GetName().GetValue() != "Current name" {
+			t.Fatalf("expected name %q, got %q", "Current name", evt.GetName().GetValue())
+		}
+		if evt.GetEventTypeID().GetValue() != "soccer" {
+			t.Fatalf("expected event type %q, got %q", "soccer", evt.GetEventTypeID().GetValue())
+		}
+		if evt.GetSequence().GetValue() != 200 {
+			t.Fatalf("expected sequence %d, got %d", 200, evt.GetSequence().GetValue())
+		}
+		return nil
+	})
+	resp, err = host.Update(ctx, &core.UpdateRequest{Event: stale, Sequence: 100})
+	if err != nil {
+		t.Fatalf("unexpected error on stale update: %v", err)
+	}
+	if !resp.GetStale() {
+		t.Fatalf("expected stale update to be reported, got %+v", resp)
+	}
+
+	// newer updates are merged and their sequence persisted
+	repo.EXPECT().GetEventByID(ctx, existing.ID).Return(existing, nil)
+	repo.EXPECT().UpdateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, evt *model.Event) error {
+		if evt.GetName().GetValue() != "Stale name" {
+			t.Fatalf("expected name %q, got %q", "Stale name", evt.GetName().GetValue())
+		}
+		if evt.GetSequence().GetValue() != 300 {
+			t.Fatalf("expected sequence %d, got %d", 300, evt.GetSequence().GetValue())
+		}
+		return nil
+	})
+	resp, err = host.Update(ctx, &core.UpdateRequest{Event: stale, Sequence: 300})
+	if err != nil {
+		t.Fatalf("unexpected error on newer update: %v", err)
+	}
+	if resp.GetStale() {
+		t.Fatalf("expected newer update not to be stale, got %+v", resp)
+	}
+}
+
+func TestService_UpdateStalePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+			Transforms: []transforms.TransformClient{
+				laddertransform.NewLadderTransformClient(laddertransform.Config{}),
+			},
+		},
+		StaleUpdatePolicy: service.StaleUpdatePartial,
+	}
+
+	ctx := context.Background()
+	priced := func(price float64) *model.Event {
+		return &model.Event{
+			ID: "unit-stale-price-1",
+			Markets: []*model.Market{{ID: "m1", Selections: []*model.Selection{
+				{ID: "s1", Price: &model.OptionalDouble{Value: price}},
+			}}},
+		}
+	}
+	existing := priced(2.02)
+	existing.Sequence = &model.OptionalInt64{Value: 200}
+
+	repo.EXPECT().GetEventByID(ctx, existing.ID).Return(existing, nil)
+	repo.EXPECT().UpdateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, evt *model.Event) error {
+		selection := evt.GetMarkets()[0].GetSelections()[0]
+		if selection.GetPrice().GetValue() != 2.02 || selection.GetFeedPrice() != nil || selection.GetPriceFlag() != nil {
+			t.Fatalf("expected the stored price to be unchanged, got %v", selection)
+		}
+		return nil
+	})
+	resp, err := host.Update(ctx, &core.UpdateRequest{Event: priced(1.013), Sequence: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.GetStale() {
+		t.Fatalf("expected stale update to be reported, got %+v", resp)
+	}
+}
+
+func TestService_UpdateNewerStoredMeanwhile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+		},
+	}
+
+	ctx := context.Background()
+	event := &model.Event{ID: "unit-race-1", Name: &model.OptionalString{Value: "Older name"}}
+	newer := &model.Event{
+		ID:       event.ID,
+		Name:     &model.OptionalString{Value: "Newer name"},
+		Sequence: &model.OptionalInt64{Value: 200},
+	}
+
+	// a newer update is stored between reading the event and writing it, the retry sees the update is stale
+	gomock.InOrder(
+		repo.EXPECT().GetEventByID(ctx, event.ID).Return(nil, nil),
+		repo.EXPECT().UpdateEvent(ctx, gomock.Any()).Return(repository.ErrStaleSequence),
+		repo.EXPECT().GetEventByID(ctx, event.ID).Return(newer, nil),
+	)
+	resp, err := host.Update(ctx, &core.UpdateRequest{Event: event, Sequence: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.GetStale() {
+		t.Fatalf("expected the update to be reported stale, got %+v", resp)
+	}
+	if event.GetSequence() != nil {
+		t.Fatalf("expected the request not to be changed, got sequence %v", event.GetSequence())
+	}
+}
+
+type failingTransform struct{}
+
+func (failingTransform) TransformEvent(_ context.Context, _, _ *model.Event) (*model.Event, error) {
+	return nil, errors.New("transform_failed")
+}
+
+func (failingTransform) GetName() string {
+	return "FailingTransform"
+}
+
+func TestService_UpdateTransformFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pipeline, err := transforms.NewPipeline(
+		transforms.Stage{Transform: failingTransform{}},
+		transforms.Stage{Transform: sporttransform.NewSportTransformClient(), DependsOn: []string{"FailingTransform"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+			Pipeline:     pipeline,
+		},
+	}
+
+	ctx := context.Background()
+	newEvent := &model.Event{
+		ID:          "unit-failures-1",
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+	}
+
+	repo.EXPECT().GetEventByID(ctx, newEvent.ID).Return(nil, nil)
+	repo.EXPECT().UpdateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, evt *model.Event) error {
+		if evt.GetSportData() != nil {
+			t.Fatalf("expected dependent transform to be skipped, got %+v", evt.GetSportData())
+		}
+		return nil
+	})
+
+	resp, err := host.Update(ctx, &core.UpdateRequest{Event: newEvent})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failures := resp.GetTransformFailures()
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 transform failures, got %+v", failures)
+	}
+	if failures[0].GetTransform() != "FailingTransform" || failures[0].GetError() != "transform_failed" {
+		t.Fatalf("unexpected failure %+v", failures[0])
+	}
+	if failures[1].GetTransform() != "SportsTransform" ||
+		failures[1].GetError() != transforms.ErrDependencyFailed.Error() {
+		t.Fatalf("unexpected failure %+v", failures[1])
+	}
+
+	// a transform failing the update reports the failures in the details of the error
+	host.Upstreams.Pipeline, err = transforms.NewPipeline(
+		transforms.Stage{Transform: failingTransform{}, OnError: transforms.ErrorPolicyFail},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.EXPECT().GetEventByID(ctx, newEvent.ID).Return(nil, nil)
+	_, err = host.Update(ctx, &core.UpdateRequest{Event: newEvent})
+	details := status.Convert(err).Details()
+	if status.Code(err) != codes.Internal || len(details) != 1 {
+		t.Fatalf("expected the failure in the details of the error, got %v", err)
+	}
+	if failure, ok := details[0].(*core.TransformFailure); !ok || failure.GetTransform() != "FailingTransform" {
+		t.Fatalf("unexpected failure %+v", details[0])
+	}
+}
+
 func TestService_GetSportEvent(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -156,3 +426,509 @@ func TestService_GetSportEvent(t *testing.T) {
 		t.Fatalf("expected market ID %q, got %#v", "m1", resp.Event.Markets)
 	}
 }
+
+func TestService_GetSportEventPrices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+		},
+	}
+
+	ctx := context.Background()
+	var changes []*model.PriceChange
+	for i := range core.MaxPriceChanges + 2 {
+		changes = append(changes, &model.PriceChange{Price: 2 + float64(i)/8, Timestamp: int64(i)})
+	}
+	event := &model.Event{
+		ID: "unit-prices-1",
+		Markets: []*model.Market{
+			{
+				ID: "H2H",
+				Selections: []*model.Selection{
+					{
+						ID:    "home",
+						Price: &model.OptionalDouble{Value: 3.5},
+						PriceHistory: &model.PriceHistory{
+							OpeningPrice:  &model.OptionalDouble{Value: 3},
+							PreviousPrice: &model.OptionalDouble{Value: 3.25},
+							Changes:       changes,
+						},
+					},
+					{ID: "away", Price: &model.OptionalDouble{Value: 1.5}},
+					{ID: "draw"},
+				},
+			},
+		},
+	}
+
+	repo.EXPECT().GetEventByID(ctx, event.ID).Return(event, nil)
+
+	resp, err := host.GetSportEvent(ctx, &core.GetSportEventRequest{
+		EventID:    event.ID,
+		OddsFormat: core.OddsFormat_OddsFractional,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prices := resp.GetEvent().GetPrices()
+	if len(prices) != 2 {
+		t.Fatalf("expected 2 prices, got %#v", prices)
+	}
+	home := prices[0]
+	if home.GetMarketID() != "H2H" || home.GetSelectionID() != "home" {
+		t.Fatalf("expected price for H2H/home, got %+v", home)
+	}
+	if home.GetDecimal() != 3.5 || home.GetFractional() != "5/2" || home.GetAmerican() != "+250" {
+		t.Fatalf("unexpected price formats %+v", home)
+	}
+	if home.GetDisplay() != "5/2" {
+		t.Fatalf("expected display %q, got %q", "5/2", home.GetDisplay())
+	}
+	if home.GetOpeningPrice() != 3 || home.GetPreviousPrice() != 3.25 || home.GetMovement() != core.MovementDrifting {
+		t.Fatalf("unexpected price history %+v", home)
+	}
+	got := home.GetChanges()
+	if len(got) != core.MaxPriceChanges || got[0].GetTimestamp() != 2 || got[len(got)-1].GetPrice() != 3.375 {
+		t.Fatalf("expected the %d most recent price changes, got %v", core.MaxPriceChanges, got)
+	}
+	if prices[1].GetAmerican() != "-200" || prices[1].GetDisplay() != "1/2" || prices[1].GetMovement() != "" {
+		t.Fatalf("unexpected price formats %+v", prices[1])
+	}
+}
+
+func TestService_SportTypes(t *testing.T) {
+	registry := sporttypes.NewRegistry(sporttypes.Defaults()...)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Transforms: []transforms.TransformClient{
+				sporttransform.NewSportTransformClientWithRegistry(registry),
+			},
+			SportTypes: registry,
+		},
+	}
+
+	ctx := context.Background()
+	_, err := host.UpdateSportType(ctx, &core.UpdateSportTypeRequest{
+		SportType: &core.SportType{EventTypeID: "afl", Name: "Australian Rules", League: "AFL"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := host.UpdateSportType(ctx, &core.UpdateSportTypeRequest{
+		SportType: &core.SportType{EventTypeID: "afl"},
+	}); err == nil {
+		t.Fatalf("expected an error for a sport type without a name")
+	}
+
+	resp, err := host.ListSportTypes(ctx, &core.ListSportTypesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	types := resp.GetSportTypes()
+	if len(types) != 3 || types[0].GetEventTypeID() != "afl" || types[0].GetLeague() != "AFL" {
+		t.Fatalf("unexpected sport types %+v", types)
+	}
+}
+
+func TestService_Health(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	healthy := true
+	repo.EXPECT().HealthCheck(gomock.Any()).DoAndReturn(func(context.Context) bool { return healthy }).AnyTimes()
+
+	host := service.NewService(0, 0, &service.Upstreams{MergerClient: merger.NewInlineMergerClient(), Repo: repo})
+	handler := host.HTTPHandler()
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Fatalf("expected /healthz to be OK, got %d", code)
+	}
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected /readyz to be unavailable before the service runs, got %d", code)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- host.Run()
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !host.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatalf("service did not become ready")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("expected /readyz to be OK once serving, got %d", code)
+	}
+	healthy = false
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected /readyz to be unavailable with an unhealthy repository, got %d", code)
+	}
+	healthy = true
+
+	if err := host.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected /readyz to be unavailable once stopped, got %d", code)
+	}
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Fatalf("expected /healthz to stay OK, got %d", code)
+	}
+	<-errChan
+}
+
+func TestService_REST(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+			Transforms:   []transforms.TransformClient{sporttransform.NewSportTransformClient()},
+		},
+	}
+	handler := host.HTTPHandler()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	payload, err := os.ReadFile("../../../../exampledata/01_new_event.json")
+	if err != nil {
+		t.Fatalf("failed to read example: %v", err)
+	}
+
+	var stored *model.Event
+	repo.EXPECT().GetEventByID(gomock.Any(), "testEvent").Return(nil, nil)
+	repo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, evt *model.Event) error {
+		stored = evt
+		return nil
+	})
+
+	rec := do(http.MethodPut, "/events/testEvent", string(payload))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the update to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+	resp := &core.UpdateResponse{}
+	if err := protojson.Unmarshal(rec.Body.Bytes(), resp); err != nil || resp.GetMessage() != "New Event born testEvent" {
+		t.Fatalf("unexpected response %s (error %v)", rec.Body, err)
+	}
+
+	if rec := do(http.MethodPut, "/events/otherEvent", string(payload)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an event ID not matching the path to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/events/testEvent", "{"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid body to be rejected, got %d", rec.Code)
+	}
+
+	repo.EXPECT().GetEventByID(gomock.Any(), "testEvent").Return(stored, nil)
+	rec = do(http.MethodGet, "/sport-events/testEvent?oddsFormat=OddsFractional", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the sport event to be returned, got %d: %s", rec.Code, rec.Body)
+	}
+	sportEvent := &core.GetSportEventResponse{}
+	if err := protojson.Unmarshal(rec.Body.Bytes(), sportEvent); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if sportEvent.GetEvent().GetID() != "testEvent" || sportEvent.GetEvent().GetSportName() != "Rugby League" {
+		t.Fatalf("unexpected sport event %v", sportEvent.GetEvent())
+	}
+
+	repo.EXPECT().GetEventByID(gomock.Any(), "missing").Return(nil, nil)
+	if rec := do(http.MethodGet, "/sport-events/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected a missing event to be not found, got %d", rec.Code)
+	}
+}
+
+func TestService_EventChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	broker := changes.NewBroker(changes.DefaultBuffer)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+			Transforms:   []transforms.TransformClient{sporttransform.NewSportTransformClient()},
+			Changes:      broker,
+		},
+	}
+	server := httptest.NewServer(host.HTTPHandler())
+	defer server.Close()
+
+	existing := &model.Event{
+		ID:          "stream-1",
+		Name:        &model.OptionalString{Value: "Old name"},
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+	}
+	repo.EXPECT().GetEventByID(gomock.Any(), existing.ID).Return(existing, nil).Times(2)
+	repo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+	resp, err := http.Get(server.URL + "/events/stream-1/changes")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// readEvent reads the next Server-Sent Event and decodes its data
+	readEvent := func() (string, *model.Event) {
+		var name string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read stream: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event := &model.Event{}
+				if err := protojson.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), event); err != nil {
+					t.Fatalf("failed to decode %v: %v", name, err)
+				}
+				return name, event
+			}
+		}
+	}
+
+	name, snapshot := readEvent()
+	if name != "snapshot" || snapshot.GetName().GetValue() != "Old name" {
+		t.Fatalf("expected a snapshot of the event, got %v %v", name, snapshot)
+	}
+
+	// the stream subscribes before sending the snapshot, so the update is not missed
+	update := &model.Event{ID: existing.ID, Name: &model.OptionalString{Value: "New name"}}
+	if _, err := host.Update(context.Background(), &core.UpdateRequest{Event: update}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	name, delta := readEvent()
+	if name != "delta" || delta.GetName().GetValue() != "New name" {
+		t.Fatalf("expected a delta with the new name, got %v %v", name, delta)
+	}
+	if delta.GetSportData().GetName().GetValue() != "Soccer" {
+		t.Fatalf("expected the delta to include the transform outputs, got %v", delta)
+	}
+	if len(delta.GetMarkets()) != 0 || delta.GetEventTypeID() != nil {
+		t.Fatalf("expected the delta to only hold changed values, got %v", delta)
+	}
+}
+
+func TestService_EventChangesInStoreOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	broker := changes.NewBroker(changes.DefaultBuffer)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{
+			MergerClient: merger.NewInlineMergerClient(),
+			Repo:         repo,
+			Changes:      broker,
+		},
+	}
+	sub := broker.Subscribe("ordered-1")
+	defer sub.Close()
+
+	// the first update is held right after being stored, while the second one is sent
+	var mtx sync.Mutex
+	var stored []string
+	firstStored := make(chan struct{})
+	release := make(chan struct{})
+	repo.EXPECT().GetEventByID(gomock.Any(), "ordered-1").Return(nil, nil).Times(2)
+	repo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *model.Event) error {
+			mtx.Lock()
+			stored = append(stored, event.GetName().GetValue())
+			mtx.Unlock()
+			if event.GetName().GetValue() == "first" {
+				close(firstStored)
+				<-release
+			}
+			return nil
+		}).Times(2)
+
+	update := func(name string, done chan<- error) {
+		_, err := host.Update(context.Background(), &core.UpdateRequest{
+			Event: &model.Event{ID: "ordered-1", Name: &model.OptionalString{Value: name}},
+		})
+		done <- err
+	}
+	done := make(chan error, 2)
+	go update("first", done)
+	<-firstStored
+	go update("second", done)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for i, want := range stored {
+		change := <-sub.C
+		if got := change.Delta.GetName().GetValue(); got != want {
+			t.Fatalf("expected change %d to be %v as stored in order %v, got %v", i, want, stored, got)
+		}
+	}
+}
+
+func TestService_RESTAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "dashboard", Key: "reader-key", Roles: []string{auth.RoleReader}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := mock.NewMockRepository(ctrl)
+	host := &service.Service{
+		Upstreams: &service.Upstreams{MergerClient: merger.NewInlineMergerClient(), Repo: repo},
+		Auth:      authenticator,
+	}
+	handler := host.HTTPHandler()
+	do := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		if key != "" {
+			req.Header.Set(auth.APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do(http.MethodGet, "/sport-events/testEvent", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected a request without credentials to be unauthorized, got %d", code)
+	}
+	if code := do(http.MethodPut, "/events/testEvent", "reader-key"); code != http.StatusForbidden {
+		t.Fatalf("expected readers not to update events, got %d", code)
+	}
+
+	repo.EXPECT().GetEventByID(gomock.Any(), "testEvent").Return(nil, nil)
+	if code := do(http.MethodGet, "/sport-events/testEvent", "reader-key"); code != http.StatusNotFound {
+		t.Fatalf("expected readers to get sport events, got %d", code)
+	}
+	if code := do(http.MethodGet, "/healthz", ""); code != http.StatusOK {
+		t.Fatalf("expected health checks to stay public, got %d", code)
+	}
+}
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = lis.Close() }()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func TestService_GracefulStop(t *testing.T) {
+	for name, tc := range map[string]struct {
+		timeout  time.Duration
+		wantCode codes.Code
+	}{
+		"drains in-flight updates": {timeout: 5 * time.Second, wantCode: codes.OK},
+		"stops hard on timeout":    {timeout: 100 * time.Millisecond, wantCode: codes.Unavailable},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockRepository(ctrl)
+			started, release := make(chan struct{}), make(chan struct{})
+			repo.EXPECT().GetEventByID(gomock.Any(), "testEvent").DoAndReturn(
+				func(context.Context, string) (*model.Event, error) {
+					close(started)
+					<-release
+					return nil, nil
+				})
+			repo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			broker := changes.NewBroker(changes.DefaultBuffer)
+			host := service.NewService(freePort(t), freePort(t), &service.Upstreams{
+				MergerClient: merger.NewInlineMergerClient(),
+				Repo:         repo,
+				Changes:      broker,
+			})
+			go func() { _ = host.Run() }()
+			deadline := time.Now().Add(5 * time.Second)
+			for !host.Ready() {
+				if time.Now().After(deadline) {
+					t.Fatalf("service did not become ready")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", host.GRPCPort),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("failed to dial: %v", err)
+			}
+			defer func() { _ = conn.Close() }()
+
+			updated := make(chan error, 1)
+			go func() {
+				_, err := core.NewServiceClient(conn).Update(context.Background(),
+					&core.UpdateRequest{Event: &model.Event{ID: "testEvent"}})
+				updated <- err
+			}()
+			<-started
+			sub := broker.Subscribe("")
+
+			stopped := make(chan error, 1)
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			go func() { stopped <- host.Stop(ctx) }()
+
+			if _, ok := <-sub.C; ok {
+				t.Fatalf("expected the change streams to be ended")
+			}
+			time.Sleep(200 * time.Millisecond)
+			if host.Ready() {
+				t.Fatalf("expected the service not to be ready while stopping")
+			}
+			close(release)
+
+			err = <-updated
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("expected the update to end with %v, got %v", tc.wantCode, err)
+			}
+			if err := <-stopped; (err == nil) != (tc.wantCode == codes.OK) {
+				t.Fatalf("unexpected stop error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Package sporttypes maps event types to the sport they belong to
+package sporttypes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SportType is the sport an EventTypeID maps to, with the default values of events of that sport
+type SportType struct {
+	EventTypeID string `yaml:"eventTypeID"`
+	Name        string `yaml:"name"`
+	Region      string `yaml:"region,omitempty"`
+	League      string `yaml:"league,omitempty"`
+}
+
+// file is the layout of a sport types file
+type file struct {
+	SportTypes []SportType `yaml:"sportTypes"`
+}
+
+// Defaults returns the sport types known when no file is configured
+func Defaults() []SportType {
+	return []SportType{
+		{EventTypeID: "soccer", Name: "Soccer"},
+		{EventTypeID: "rugby_league", Name: "Rugby League"},
+	}
+}
+
+// Validate checks that a sport type can be used
+func (t SportType) Validate() error {
+	if t.EventTypeID == "" {
+		return fmt.Errorf("sport type has no eventTypeID")
+	}
+	if t.Name == "" {
+		return fmt.Errorf("sport type %v has no name", t.EventTypeID)
+	}
+	return nil
+}
+
+// Registry holds the EventTypeID to SportType mapping. A registry loaded from a file is reloaded when the file changes
+// and writes updates back to it.
+type Registry struct {
+	mtx     sync.RWMutex
+	path    string
+	modTime time.Time
+	types   map[string]SportType
+}
+
+// NewRegistry creates an in memory Registry of the given sport types
+func NewRegistry(types ...SportType) *Registry {
+	r := &Registry{types: make(map[string]SportType, len(types))}
+	for _, t := range types {
+		r.types[t.EventTypeID] = t
+	}
+	return r
+}
+
+// LoadRegistry creates a Registry from a YAML file
+func LoadRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get returns the sport type of an EventTypeID
+func (r *Registry) Get(eventTypeID string) (SportType, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	t, ok := r.types[eventTypeID]
+	return t, ok
+}
+
+// EventTypeIDs returns every EventTypeID with a sport type, sorted
+func (r *Registry) EventTypeIDs() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return slices.Sorted(maps.Keys(r.types))
+}
+
+// List returns every sport type, sorted by EventTypeID
+func (r *Registry) List() []SportType {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	types := make([]SportType, 0, len(r.types))
+	for _, id := range slices.Sorted(maps.Keys(r.types)) {
+		types = append(types, r.types[id])
+	}
+	return types
+}
+
+// Set adds or replaces a sport type, writing the mapping back to the file of the registry if it has one
+func (r *Registry) Set(t SportType) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	types := maps.Clone(r.types)
+	types[t.EventTypeID] = t
+	if r.path != "" {
+		if err := r.write(types); err != nil {
+			return err
+		}
+	}
+	r.types = types
+
+	return nil
+}
+
+// Reload reads the file of the registry again if it changed since it was last read, and reports whether it did
+func (r *Registry) Reload() (bool, error) {
+	if r.path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, fmt.Errorf("failed_to_read_sport_types: %w", err)
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if info.ModTime().Equal(r.modTime) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return false, fmt.Errorf("failed_to_read_sport_types: %w", err)
+	}
+	f := &file{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return false, fmt.Errorf("failed_to_parse_sport_types: %w", err)
+	}
+
+	types := make(map[string]SportType, len(f.SportTypes))
+	for _, t := range f.SportTypes {
+		if err := t.Validate(); err != nil {
+			return false, err
+		}
+		types[t.EventTypeID] = t
+	}
+
+	r.types = types
+	r.modTime = info.ModTime()
+	return true, nil
+}
+
+// Watch reloads the registry whenever its file changes, until the context is cancelled
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	if r.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				// keep the last good mapping until the file is fixed
+				logrus.WithError(err).WithField("path", r.path).Error("sport_types_reload_failed")
+				continue
+			}
+			if reloaded {
+				logrus.WithField("path", r.path).Info("sport_types_reloaded")
+			}
+		}
+	}
+}
+
+// write replaces the file of the registry with the given mapping, the lock must be held
+func (r *Registry) write(types map[string]SportType) error {
+	f := &file{}
+	for _, id := range slices.Sorted(maps.Keys(types)) {
+		f.SportTypes = append(f.SportTypes, types[id])
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a reload never sees a partially written file
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed_to_write_sport_types: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if info, err := os.Stat(r.path); err == nil {
+		_ = tmp.Chmod(info.Mode().Perm())
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed_to_write_sport_types: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed_to_write_sport_types: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed_to_write_sport_types: %w", err)
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("failed_to_write_sport_types: %w", err)
+	}
+	r.modTime = info.ModTime()
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package sporttypes_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write sport types: %v", err)
+	}
+	// set the modification time explicitly, writes within the same clock tick would not be seen as changes
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+}
+
+func TestRegistry_LoadAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sporttypes.yaml")
+	writeFile(t, path, "sportTypes:\n  - {eventTypeID: soccer, name: Soccer, region: Europe}\n", time.Unix(1, 0))
+
+	registry, err := sporttypes.LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	soccer, ok := registry.Get("soccer")
+	if !ok || soccer.Name != "Soccer" || soccer.Region != "Europe" {
+		t.Fatalf("unexpected sport type %+v", soccer)
+	}
+
+	reloaded, err := registry.Reload()
+	if err != nil || reloaded {
+		t.Fatalf("expected no reload of an unchanged file, got %v, %v", reloaded, err)
+	}
+
+	writeFile(t, path, "sportTypes:\n  - {eventTypeID: soccer, name: Football}\n  - {eventTypeID: afl, name: AFL}\n",
+		time.Unix(2, 0))
+	reloaded, err = registry.Reload()
+	if err != nil || !reloaded {
+		t.Fatalf("expected the changed file to be reloaded, got %v, %v", reloaded, err)
+	}
+	if got := registry.EventTypeIDs(); !reflect.DeepEqual(got, []string{"afl", "soccer"}) {
+		t.Fatalf("unexpected event types %v", got)
+	}
+
+	writeFile(t, path, "sportTypes:\n  - {eventTypeID: soccer}\n", time.Unix(3, 0))
+	if _, err := registry.Reload(); err == nil {
+		t.Fatalf("expected an error for a sport type without a name")
+	}
+	if soccer, _ := registry.Get("soccer"); soccer.Name != "Football" {
+		t.Fatalf("expected the last good mapping to be kept, got %+v", soccer)
+	}
+}
+
+func TestRegistry_SetWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sporttypes.yaml")
+	writeFile(t, path, "sportTypes:\n  - {eventTypeID: soccer, name: Soccer}\n", time.Unix(1, 0))
+
+	registry, err := sporttypes.LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Set(sporttypes.SportType{EventTypeID: "tennis", Name: "Tennis"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Set(sporttypes.SportType{EventTypeID: "golf"}); err == nil {
+		t.Fatalf("expected an error for a sport type without a name")
+	}
+
+	reread, err := sporttypes.LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(reread.List(), registry.List()) || len(reread.List()) != 2 {
+		t.Fatalf("expected the file to hold the updated mapping, got %+v", reread.List())
+	}
+}
@@ -0,0 +1,117 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository/mock"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/tracing"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// nameTransform names the event
+type nameTransform struct{}
+
+func (nameTransform) TransformEvent(_ context.Context, _, fullModel *model.Event) (*model.Event, error) {
+	return &model.Event{ID: fullModel.GetID(), Name: &model.OptionalString{Value: "Named"}}, nil
+}
+
+func (nameTransform) GetName() string {
+	return "NameTransform"
+}
+
+func TestTracing_Update(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterNone}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock.NewMockRepository(ctrl)
+	repo.EXPECT().GetEventByID(gomock.Any(), "evt-1").Return(&model.Event{ID: "evt-1"}, nil)
+	repo.EXPECT().UpdateEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+	tracedRepo := tracing.TraceRepository(repo)
+	tracedMerger := tracing.TraceMerger(merger.NewInlineMergerClient())
+	pipeline := transforms.NewSequentialPipeline(tracing.TraceTransform(nameTransform{}))
+
+	// an update the way Service.Update handles it
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		update := &model.Event{ID: "evt-1"}
+		existing, err := tracedRepo.GetEventByID(ctx, update.GetID())
+		if err != nil {
+			return nil, err
+		}
+		merged, err := tracedMerger.MergeEvent(ctx, existing, update)
+		if err != nil {
+			return nil, err
+		}
+		merged, _, err = pipeline.Run(ctx, tracedMerger, update, merged)
+		if err != nil {
+			return nil, err
+		}
+		return nil, tracedRepo.UpdateEvent(ctx, merged)
+	}
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/core.Service/Update"}
+	req := &core.UpdateRequest{Event: &model.Event{ID: "evt-1"}}
+	if _, err := tracing.UnaryServerInterceptor()(ctx, req, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		names[span.Name()] = span
+	}
+	rpc, ok := names["core.Service/Update"]
+	if !ok {
+		t.Fatalf("expected a span for the RPC, got %d spans", len(spans))
+	}
+	if rpc.SpanContext().TraceID().String() != traceID {
+		t.Fatalf("expected the RPC span to continue the trace of the caller, got %v", rpc.SpanContext().TraceID())
+	}
+
+	for _, name := range []string{
+		"core.Service/Update", "Repository.GetEventByID", "Merger.MergeEvent", "Transform NameTransform",
+		"Repository.UpdateEvent",
+	} {
+		span, ok := names[name]
+		if !ok {
+			t.Fatalf("expected a %v span", name)
+		}
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Fatalf("expected the %v span to be part of the RPC trace", name)
+		}
+		var eventID string
+		for _, attr := range span.Attributes() {
+			if attr.Key == tracing.EventIDKey {
+				eventID = attr.Value.AsString()
+			}
+		}
+		if eventID != "evt-1" {
+			t.Fatalf("expected the %v span to carry the event ID, got %q", name, eventID)
+		}
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"}); err == nil {
+		t.Fatalf("expected an error for an unknown exporter")
+	}
+}
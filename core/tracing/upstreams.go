@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/repository"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// TransformKey is the attribute holding the name of the transform a span runs
+const TransformKey = attribute.Key("transform.name")
+
+type tracedRepository struct {
+	repo repository.Repository
+}
+
+// TraceRepository starts a span for every call to a repository that reads or writes events
+func TraceRepository(repo repository.Repository) repository.Repository {
+	return &tracedRepository{repo: repo}
+}
+
+func (r *tracedRepository) HealthCheck(ctx context.Context) bool {
+	return r.repo.HealthCheck(ctx)
+}
+
+func (r *tracedRepository) GetEventByID(ctx context.Context, id string) (*model.Event, error) {
+	ctx, span := Start(ctx, "Repository.GetEventByID", EventIDKey.String(id))
+	event, err := r.repo.GetEventByID(ctx, id)
+	End(span, err)
+	return event, err
+}
+
+func (r *tracedRepository) UpdateEvent(ctx context.Context, event *model.Event) error {
+	ctx, span := Start(ctx, "Repository.UpdateEvent", EventIDKey.String(event.GetID()))
+	err := r.repo.UpdateEvent(ctx, event)
+	End(span, err)
+	return err
+}
+
+func (r *tracedRepository) DeleteEventByID(ctx context.Context, id string) error {
+	ctx, span := Start(ctx, "Repository.DeleteEventByID", EventIDKey.String(id))
+	err := r.repo.DeleteEventByID(ctx, id)
+	End(span, err)
+	return err
+}
+
+func (r *tracedRepository) GetEventIDsByStartTime(ctx context.Context, from, to time.Time) ([]string, error) {
+	ctx, span := Start(ctx, "Repository.GetEventIDsByStartTime")
+	ids, err := r.repo.GetEventIDsByStartTime(ctx, from, to)
+	End(span, err)
+	return ids, err
+}
+
+type tracedMerger struct {
+	client merger.ServiceClient
+}
+
+// TraceMerger starts a span for every merge
+func TraceMerger(client merger.ServiceClient) merger.ServiceClient {
+	return &tracedMerger{client: client}
+}
+
+func (m *tracedMerger) MergeEvent(ctx context.Context, left, right *model.Event) (*model.Event, error) {
+	id := right.GetID()
+	if id == "" {
+		id = left.GetID()
+	}
+	ctx, span := Start(ctx, "Merger.MergeEvent", EventIDKey.String(id))
+	event, err := m.client.MergeEvent(ctx, left, right)
+	End(span, err)
+	return event, err
+}
+
+// TraceTransform starts a span for every run of a transform
+func TraceTransform(transform transforms.TransformClient) transforms.TransformClient {
+	name := transform.GetName()
+	return transforms.Wrap(transform, func(ctx context.Context, partialUpdate, fullModel *model.Event) (
+		*model.Event, error,
+	) {
+		ctx, span := Start(ctx, "Transform "+name, TransformKey.String(name), EventIDKey.String(fullModel.GetID()))
+		delta, err := transform.TransformEvent(ctx, partialUpdate, fullModel)
+		End(span, err)
+		return delta, err
+	})
+}
@@ -0,0 +1,159 @@
+// Package laddertransform supplies a ladderTransformClient
+package laddertransform
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/ladder"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Flags recorded on Selection.PriceFlag
+const (
+	FlagSnapped       = "snapped"
+	FlagBelowMinimum  = "below_minimum"
+	FlagRejectedPrice = "rejected_below_minimum"
+)
+
+// BelowMinimumPolicy controls what happens to prices below the minimum price
+type BelowMinimumPolicy int
+
+const (
+	// FlagBelowMinimumPrice keeps the price but flags it for a trader to look at
+	FlagBelowMinimumPrice BelowMinimumPolicy = iota
+	// RejectBelowMinimumPrice removes the price from the selection
+	RejectBelowMinimumPrice
+)
+
+// Config holds the ladder prices are snapped onto and how prices below the minimum are handled
+type Config struct {
+	Ladder   *ladder.Ladder // defaults to the Betfair ladder
+	MinPrice float64        // defaults to the lowest price of the ladder
+	Policy   BelowMinimumPolicy
+}
+
+type ladderTransformClient struct {
+	ladder   *ladder.Ladder
+	minPrice float64
+	policy   BelowMinimumPolicy
+}
+
+// NewLadderTransformClient creates a new Ladder transform client
+func NewLadderTransformClient(config Config) transforms.TransformClient {
+	l := config.Ladder
+	if l == nil {
+		l = ladder.Betfair()
+	}
+	minPrice := config.MinPrice
+	if minPrice == 0 {
+		minPrice = l.Min()
+	}
+
+	return &ladderTransformClient{ladder: l, minPrice: minPrice, policy: config.Policy}
+}
+
+// TransformEvent snaps the selection prices changed in this update onto the ladder
+func (t *ladderTransformClient) TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	var outDelta *model.Event
+
+	current := make(map[string]map[string]*model.Selection, len(fullModel.GetMarkets()))
+	for _, market := range fullModel.GetMarkets() {
+		selections := make(map[string]*model.Selection, len(market.GetSelections()))
+		for _, selection := range market.GetSelections() {
+			selections[selection.GetID()] = selection
+		}
+		current[market.GetID()] = selections
+	}
+
+	var markets []*model.Market
+	for _, market := range partialUpdate.GetMarkets() {
+		var selections []*model.Selection
+		for _, selection := range market.GetSelections() {
+			if selection.GetPrice() == nil || selection.GetPrice().GetDeleted() {
+				continue // the price didnt change on this update
+			}
+			// a stale update is merged underneath the stored event, whose newer price wins and is the one snapped
+			existing := current[market.GetID()][selection.GetID()]
+			price := existing.GetPrice()
+			if price == nil || price.GetDeleted() {
+				continue
+			}
+			fed := selection.GetPrice().GetValue() == price.GetValue()
+			delta := t.transformPrice(logging.FromContext(ctx), selection.GetID(), price.GetValue(), fed, existing)
+			if delta != nil {
+				selections = append(selections, delta)
+			}
+		}
+		if len(selections) > 0 {
+			markets = append(markets, &model.Market{ID: market.GetID(), Selections: selections})
+		}
+	}
+
+	if len(markets) == 0 {
+		return outDelta, nil
+	}
+
+	outDelta = &model.Event{
+		ID:      partialUpdate.ID,
+		Markets: markets,
+	}
+
+	return outDelta, nil
+}
+
+// transformPrice returns the delta for a single selection, or nil if the selection does not need to change. fed
+// reports whether the price is the one of this update, rather than a newer stored price it lost to.
+func (t *ladderTransformClient) transformPrice(logger *logrus.Entry, id string, price float64, fed bool,
+	existing *model.Selection,
+) *model.Selection {
+	if price < t.minPrice {
+		logger.WithField("selection", id).WithField("price", price).Warn("price below minimum price")
+		if t.policy == RejectBelowMinimumPrice {
+			return &model.Selection{
+				ID:        id,
+				Price:     &model.OptionalDouble{Deleted: true},
+				FeedPrice: &model.OptionalDouble{Value: price},
+				PriceFlag: &model.OptionalString{Value: FlagRejectedPrice},
+			}
+		}
+		return &model.Selection{
+			ID:        id,
+			FeedPrice: &model.OptionalDouble{Value: price},
+			PriceFlag: &model.OptionalString{Value: FlagBelowMinimum},
+		}
+	}
+
+	snapped := t.ladder.Snap(price)
+	if snapped != price {
+		return &model.Selection{
+			ID:        id,
+			Price:     &model.OptionalDouble{Value: snapped},
+			FeedPrice: &model.OptionalDouble{Value: price},
+			PriceFlag: &model.OptionalString{Value: FlagSnapped},
+		}
+	}
+
+	if existing.GetPriceFlag() == nil || existing.GetPriceFlag().GetDeleted() {
+		return nil // the price is on the ladder and there is no audit trail to clear
+	}
+	if !fed {
+		return nil // the audit trail belongs to the stored price, which this update did not change
+	}
+
+	// the price is valid again so the audit of the previous price no longer applies
+	return &model.Selection{
+		ID:        id,
+		FeedPrice: &model.OptionalDouble{Deleted: true},
+		PriceFlag: &model.OptionalString{Deleted: true},
+	}
+}
+
+func (t *ladderTransformClient) GetName() string {
+	return "LadderTransform"
+}
@@ -0,0 +1,119 @@
+package laddertransform_test
+
+import (
+	"context"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/laddertransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func pricedEvent(id string, price float64) *model.Event {
+	return &model.Event{
+		ID: id,
+		Markets: []*model.Market{
+			{ID: "m1", Selections: []*model.Selection{{ID: "s1", Price: &model.OptionalDouble{Value: price}}}},
+		},
+	}
+}
+
+func TestTransformEvent_SkipsWhenNoPricesUpdated(t *testing.T) {
+	client := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+
+	partial := &model.Event{
+		ID:      "evt-1",
+		Markets: []*model.Market{{ID: "m1", Name: &model.OptionalString{Value: "Head to Head"}}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, pricedEvent("evt-1", 1.013))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when no prices updated, got %#v", out)
+	}
+}
+
+func TestTransformEvent_SnapsPriceOntoLadder(t *testing.T) {
+	client := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-2", 1.013), pricedEvent("evt-2", 1.013))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected output event, got nil")
+	}
+
+	selection := out.Markets[0].Selections[0]
+	if selection.GetPrice().GetValue() != 1.01 {
+		t.Fatalf("expected price %v, got %v", 1.01, selection.GetPrice().GetValue())
+	}
+	if selection.GetFeedPrice().GetValue() != 1.013 || selection.GetPriceFlag().GetValue() != laddertransform.FlagSnapped {
+		t.Fatalf("expected original price to be recorded, got %+v", selection)
+	}
+}
+
+func TestTransformEvent_BelowMinimumPrice(t *testing.T) {
+	flag := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+	out, err := flag.TransformEvent(context.Background(), pricedEvent("evt-3", 0.5), pricedEvent("evt-3", 0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	selection := out.Markets[0].Selections[0]
+	if selection.Price != nil || selection.GetPriceFlag().GetValue() != laddertransform.FlagBelowMinimum {
+		t.Fatalf("expected price to be kept and flagged, got %+v", selection)
+	}
+
+	reject := laddertransform.NewLadderTransformClient(laddertransform.Config{
+		Policy: laddertransform.RejectBelowMinimumPrice,
+	})
+	out, err = reject.TransformEvent(context.Background(), pricedEvent("evt-3", 0.5), pricedEvent("evt-3", 0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	selection = out.Markets[0].Selections[0]
+	if !selection.GetPrice().GetDeleted() || selection.GetFeedPrice().GetValue() != 0.5 ||
+		selection.GetPriceFlag().GetValue() != laddertransform.FlagRejectedPrice {
+		t.Fatalf("expected price to be rejected, got %+v", selection)
+	}
+}
+
+func TestTransformEvent_ClearsFlagWhenPriceValid(t *testing.T) {
+	client := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+
+	full := pricedEvent("evt-4", 1.5)
+	full.Markets[0].Selections[0].PriceFlag = &model.OptionalString{Value: laddertransform.FlagSnapped}
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-4", 1.5), full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || !out.Markets[0].Selections[0].GetPriceFlag().GetDeleted() {
+		t.Fatalf("expected price flag to be cleared, got %#v", out)
+	}
+}
+
+func TestTransformEvent_SnapsMergedPrice(t *testing.T) {
+	client := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+
+	// a stale update lost to the newer stored price, which was snapped when it was stored
+	full := pricedEvent("evt-5", 2.02)
+	full.Markets[0].Selections[0].FeedPrice = &model.OptionalDouble{Value: 2.013}
+	full.Markets[0].Selections[0].PriceFlag = &model.OptionalString{Value: laddertransform.FlagSnapped}
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-5", 1.013), full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected the stored price and its audit to be kept, got %#v", out)
+	}
+}
+
+func TestGetName(t *testing.T) {
+	client := laddertransform.NewLadderTransformClient(laddertransform.Config{})
+	if got := client.GetName(); got != "LadderTransform" {
+		t.Fatalf("expected name %q, got %q", "LadderTransform", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Package overroundtransform supplies an overroundTransformClient
+package overroundtransform
+
+import (
+	"context"
+	"math"
+
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Config holds the margin bounds markets are validated against. The margin of a market is its overround minus one,
+// so a 105% book has a margin of 0.05.
+type Config struct {
+	MinMargin   float64 // markets below this margin are warned about, and suspended when AutoSuspend is set
+	MaxMargin   float64 // markets above this margin are warned about, zero disables the check
+	AutoSuspend bool
+}
+
+type overroundTransformClient struct {
+	config Config
+}
+
+// NewOverroundTransformClient creates a new Overround transform client
+func NewOverroundTransformClient(config Config) transforms.TransformClient {
+	return &overroundTransformClient{config: config}
+}
+
+// TransformEvent calculates the overround of every market that changed in this update
+func (t *overroundTransformClient) TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	var outDelta *model.Event
+	if len(partialUpdate.GetMarkets()) == 0 {
+		return outDelta, nil // no market changed on this update so the books are unchanged
+	}
+
+	changed := make(map[string]bool, len(partialUpdate.GetMarkets()))
+	for _, market := range partialUpdate.GetMarkets() {
+		changed[market.GetID()] = true
+	}
+
+	var markets []*model.Market
+	for _, market := range fullModel.GetMarkets() {
+		if !changed[market.GetID()] {
+			continue
+		}
+		if delta := t.transformMarket(logging.FromContext(ctx), fullModel.GetID(), market); delta != nil {
+			markets = append(markets, delta)
+		}
+	}
+
+	if len(markets) == 0 {
+		return outDelta, nil
+	}
+
+	outDelta = &model.Event{
+		ID:      partialUpdate.ID,
+		Markets: markets,
+	}
+
+	return outDelta, nil
+}
+
+// transformMarket returns the delta for a single market, or nil if the market does not need to change
+func (t *overroundTransformClient) transformMarket(logger *logrus.Entry, eventID string,
+	market *model.Market,
+) *model.Market {
+	overround, priced := Overround(market)
+	if priced == 0 {
+		if market.GetOverround() == nil || market.GetOverround().GetDeleted() {
+			return nil
+		}
+		// no open priced selections are left so there is no book anymore
+		return &model.Market{ID: market.GetID(), Overround: &model.OptionalDouble{Deleted: true}}
+	}
+
+	var delta *model.Market
+	if current := market.GetOverround(); current == nil || current.GetDeleted() || current.GetValue() != overround {
+		delta = &model.Market{ID: market.GetID(), Overround: &model.OptionalDouble{Value: overround}}
+	}
+
+	if priced < 2 {
+		return delta // a book of a single selection says nothing about the margin
+	}
+
+	margin := overround - 1
+	tooLow := margin < t.config.MinMargin
+	tooHigh := t.config.MaxMargin != 0 && margin > t.config.MaxMargin
+	if !tooLow && !tooHigh {
+		return delta
+	}
+
+	logger = logger.WithField("event", eventID).WithField("market", market.GetID()).WithField("overround", overround)
+	logger.Warn("market margin outside of configured bounds")
+
+	if tooLow && t.config.AutoSuspend && market.GetBettingStatus().GetValue() == model.BettingStatus_BettingOpen {
+		logger.Warn("suspending market with margin below minimum")
+		if delta == nil {
+			delta = &model.Market{ID: market.GetID()}
+		}
+		delta.BettingStatus = &model.OptionalBettingStatus{Value: model.BettingStatus_BettingSuspended}
+	}
+
+	return delta
+}
+
+// Overround calculates the book percentage of the open, priced selections of a market, along with the number of
+// selections that made up the book
+func Overround(market *model.Market) (float64, int) {
+	book := 0.0
+	priced := 0
+	for _, selection := range market.GetSelections() {
+		if selection.GetBettingStatus().GetValue() != model.BettingStatus_BettingOpen {
+			continue
+		}
+		price := selection.GetPrice()
+		if price == nil || price.GetDeleted() || price.GetValue() <= 1 {
+			continue
+		}
+		book += 1 / price.GetValue()
+		priced++
+	}
+
+	return math.Round(book*10000) / 10000, priced
+}
+
+func (t *overroundTransformClient) GetName() string {
+	return "OverroundTransform"
+}
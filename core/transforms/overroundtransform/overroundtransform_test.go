@@ -0,0 +1,126 @@
+package overroundtransform_test
+
+import (
+	"context"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/overroundtransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func openSelection(id string, price float64) *model.Selection {
+	return &model.Selection{
+		ID:            id,
+		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+		Price:         &model.OptionalDouble{Value: price},
+	}
+}
+
+func TestTransformEvent_SkipsWhenNoMarketsUpdated(t *testing.T) {
+	client := overroundtransform.NewOverroundTransformClient(overroundtransform.Config{})
+
+	partial := &model.Event{ID: "evt-1", Name: &model.OptionalString{Value: "Renamed"}}
+	full := &model.Event{
+		ID:      "evt-1",
+		Markets: []*model.Market{{ID: "m1", Selections: []*model.Selection{openSelection("s1", 2)}}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when no markets updated, got %#v", out)
+	}
+}
+
+func TestTransformEvent_SetsOverround(t *testing.T) {
+	client := overroundtransform.NewOverroundTransformClient(overroundtransform.Config{})
+
+	partial := &model.Event{ID: "evt-2", Markets: []*model.Market{{ID: "m1"}}}
+	full := &model.Event{
+		ID: "evt-2",
+		Markets: []*model.Market{
+			{
+				ID: "m1",
+				Selections: []*model.Selection{
+					openSelection("s1", 1.8),
+					openSelection("s2", 2.1),
+					{ID: "s3", Price: &model.OptionalDouble{Value: 1.5}}, // not open so not in the book
+				},
+			},
+			{ID: "m2", Selections: []*model.Selection{openSelection("s1", 1.5), openSelection("s2", 1.5)}},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out.Markets) != 1 {
+		t.Fatalf("expected a delta for market m1 only, got %#v", out)
+	}
+	if got := out.Markets[0].GetOverround().GetValue(); got != 1.0317 {
+		t.Fatalf("expected overround %v, got %v", 1.0317, got)
+	}
+	if out.Markets[0].BettingStatus != nil {
+		t.Fatalf("expected betting status to be untouched, got %v", out.Markets[0].BettingStatus)
+	}
+}
+
+func TestTransformEvent_SuspendsBelowMinimumMargin(t *testing.T) {
+	client := overroundtransform.NewOverroundTransformClient(overroundtransform.Config{
+		MinMargin:   0.02,
+		AutoSuspend: true,
+	})
+
+	partial := &model.Event{ID: "evt-3", Markets: []*model.Market{{ID: "m1"}}}
+	full := &model.Event{
+		ID: "evt-3",
+		Markets: []*model.Market{
+			{
+				ID:            "m1",
+				BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+				Selections:    []*model.Selection{openSelection("s1", 2.1), openSelection("s2", 2.1)},
+			},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out.Markets) != 1 {
+		t.Fatalf("expected a delta for market m1, got %#v", out)
+	}
+	if got := out.Markets[0].GetBettingStatus().GetValue(); got != model.BettingStatus_BettingSuspended {
+		t.Fatalf("expected market to be suspended, got %v", got)
+	}
+}
+
+func TestTransformEvent_RemovesOverroundWithoutBook(t *testing.T) {
+	client := overroundtransform.NewOverroundTransformClient(overroundtransform.Config{})
+
+	partial := &model.Event{ID: "evt-4", Markets: []*model.Market{{ID: "m1"}}}
+	full := &model.Event{
+		ID: "evt-4",
+		Markets: []*model.Market{
+			{ID: "m1", Overround: &model.OptionalDouble{Value: 1.05}},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || !out.Markets[0].GetOverround().GetDeleted() {
+		t.Fatalf("expected overround to be deleted, got %#v", out)
+	}
+}
+
+func TestGetName(t *testing.T) {
+	client := overroundtransform.NewOverroundTransformClient(overroundtransform.Config{})
+	if got := client.GetName(); got != "OverroundTransform" {
+		t.Fatalf("expected name %q, got %q", "OverroundTransform", got)
+	}
+}
@@ -0,0 +1,316 @@
+package transforms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// ErrDependencyFailed is reported for transforms that did not run because a transform they depend on failed
+var ErrDependencyFailed = errors.New("dependency_failed")
+
+// ErrorPolicy controls what happens to an update when a transform fails
+type ErrorPolicy int
+
+const (
+	// ErrorPolicySkip discards the output of the failed transform, skips the transforms depending on it and carries on
+	ErrorPolicySkip ErrorPolicy = iota
+	// ErrorPolicyFail fails the whole update
+	ErrorPolicyFail
+	// ErrorPolicyRetry runs the transform again, up to Stage.Retries times, before skipping it
+	ErrorPolicyRetry
+)
+
+// ParseErrorPolicy parses the name of an error policy as used in configuration: skip, fail or retry
+func ParseErrorPolicy(name string) (ErrorPolicy, error) {
+	switch name {
+	case "", "skip":
+		return ErrorPolicySkip, nil
+	case "fail":
+		return ErrorPolicyFail, nil
+	case "retry":
+		return ErrorPolicyRetry, nil
+	default:
+		return ErrorPolicySkip, fmt.Errorf("unknown error policy %q", name)
+	}
+}
+
+// DefaultBackoff is the wait before the first retry of a stage without a Backoff
+const DefaultBackoff = 10 * time.Millisecond
+
+// MaxBackoff bounds the wait between retries as it doubles
+const MaxBackoff = 5 * time.Second
+
+// Stage is a transform of a Pipeline along with the transforms it depends on, the events it applies to and how its
+// errors are handled
+type Stage struct {
+	Transform    TransformClient
+	DependsOn    []string // names of the transforms that have to run first, as returned by GetName
+	OnError      ErrorPolicy
+	Retries      int           // number of retries with ErrorPolicyRetry
+	Backoff      time.Duration // wait before the first retry, doubled up to MaxBackoff, defaults to DefaultBackoff
+	EventTypeIDs []string      // event types the transform applies to, defaults to those of a Scoped transform
+	When         Predicate     // optional, the transform only runs when it returns true
+	WithOutputs  bool          // the partial update includes the outputs of the transforms run before this one
+}
+
+// StageStats counts how often a transform of a pipeline was run, skipped as it did not apply, or failed
+type StageStats struct {
+	Transform string
+	Runs      uint64
+	Skipped   uint64
+	Failures  uint64
+}
+
+// counters are the live counts behind StageStats
+type counters struct {
+	runs     atomic.Uint64
+	skipped  atomic.Uint64
+	failures atomic.Uint64
+}
+
+// Failure describes a transform that failed, or was skipped, while running a pipeline
+type Failure struct {
+	Transform string
+	Err       error
+}
+
+// Pipeline runs transforms in the order required by their dependencies
+type Pipeline struct {
+	stages   []Stage
+	counters []*counters
+}
+
+// NewPipeline creates a Pipeline, ordering the stages so every transform runs after the transforms it depends on.
+// Stages without dependencies between them keep the order they are given in.
+func NewPipeline(stages ...Stage) (*Pipeline, error) {
+	index := make(map[string]int, len(stages))
+	for i, stage := range stages {
+		name := stage.Transform.GetName()
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("duplicate transform %v", name)
+		}
+		index[name] = i
+	}
+
+	dependents := make([][]int, len(stages))
+	pending := make([]int, len(stages))
+	for i, stage := range stages {
+		for _, dep := range stage.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("transform %v depends on unknown transform %v", stage.Transform.GetName(), dep)
+			}
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+
+	ordered := make([]Stage, 0, len(stages))
+	done := make([]bool, len(stages))
+	for len(ordered) < len(stages) {
+		next := -1
+		for i := range stages {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("transform dependencies contain a cycle")
+		}
+		done[next] = true
+		ordered = append(ordered, stages[next])
+		for _, i := range dependents[next] {
+			pending[i]--
+		}
+	}
+
+	return newPipeline(ordered), nil
+}
+
+// NewSequentialPipeline creates a Pipeline running the transforms in the order given, skipping transforms that fail
+func NewSequentialPipeline(transforms ...TransformClient) *Pipeline {
+	stages := make([]Stage, 0, len(transforms))
+	for _, t := range transforms {
+		stages = append(stages, Stage{Transform: t})
+	}
+	return newPipeline(stages)
+}
+
+func newPipeline(stages []Stage) *Pipeline {
+	p := &Pipeline{stages: stages, counters: make([]*counters, len(stages))}
+	for i := range stages {
+		p.counters[i] = &counters{}
+	}
+	return p
+}
+
+// Stats returns how often every transform of the pipeline ran, in the order they run
+func (p *Pipeline) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(p.stages))
+	for i, stage := range p.stages {
+		stats = append(stats, StageStats{
+			Transform: stage.Transform.GetName(),
+			Runs:      p.counters[i].runs.Load(),
+			Skipped:   p.counters[i].skipped.Load(),
+			Failures:  p.counters[i].failures.Load(),
+		})
+	}
+	return stats
+}
+
+// Names returns the names of the transforms in the order they run
+func (p *Pipeline) Names() []string {
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.Transform.GetName())
+	}
+	return names
+}
+
+// Run runs every transform on the update, merging each output into the full model before the next transform runs.
+// The failures of skipped transforms are returned along with the transformed model, an error is returned when a
+// transform with ErrorPolicyFail fails or an output cannot be merged.
+func (p *Pipeline) Run(ctx context.Context, mergerClient merger.ServiceClient, partialUpdate, fullModel *model.Event) (
+	*model.Event, []Failure, error,
+) {
+	update, _, failures, err := p.RunWithOutputs(ctx, mergerClient, partialUpdate, fullModel)
+	return update, failures, err
+}
+
+// RunWithOutputs is Run also returning the output of every transform that changed the model, in the order they ran
+func (p *Pipeline) RunWithOutputs(ctx context.Context, mergerClient merger.ServiceClient,
+	partialUpdate, fullModel *model.Event,
+) (*model.Event, []*model.Event, []Failure, error) {
+	var outputs []*model.Event
+	var failures []Failure
+	failed := map[string]bool{}
+
+	update := fullModel
+	for i, stage := range p.stages {
+		name := stage.Transform.GetName()
+		stageCtx := logging.WithFields(ctx, logrus.Fields{"transform": name})
+		logger := logging.FromContext(stageCtx)
+		stagePartial := partialUpdate
+		if stage.WithOutputs {
+			stagePartial = withOutputs(ctx, partialUpdate, outputs)
+		}
+		if !applies(stage, stagePartial, update) {
+			p.counters[i].skipped.Add(1)
+			continue
+		}
+
+		if dep := failedDependency(stage, failed); dep != "" {
+			logger.Warnf("Update: skipped transform %v as %v failed", name, dep)
+			failed[name] = true
+			failures = append(failures, Failure{Transform: name, Err: ErrDependencyFailed})
+			if stage.OnError == ErrorPolicyFail {
+				return nil, nil, failures, fmt.Errorf("transform %v failed: %w", name, ErrDependencyFailed)
+			}
+			continue
+		}
+
+		p.counters[i].runs.Add(1)
+		upd, err := runStage(stageCtx, stage, stagePartial, update)
+		if err != nil {
+			p.counters[i].failures.Add(1)
+			logger.WithError(err).Errorf("Update: failed to run transform %v", name)
+			failures = append(failures, Failure{Transform: name, Err: err})
+			if stage.OnError == ErrorPolicyFail {
+				return nil, nil, failures, fmt.Errorf("transform %v failed: %w", name, err)
+			}
+			failed[name] = true
+			continue
+		}
+
+		if upd != nil {
+			outputs = append(outputs, upd)
+			update, err = mergerClient.MergeEvent(ctx, update, upd)
+			if err != nil {
+				logger.WithError(err).Errorf("Update: failed to merge event in transform %v", name)
+				return nil, nil, failures, err
+			}
+		}
+	}
+
+	return update, outputs, failures, nil
+}
+
+// withOutputs returns the partial update along with the changes made by the outputs of the transforms
+func withOutputs(ctx context.Context, partialUpdate *model.Event, outputs []*model.Event) *model.Event {
+	changed := partialUpdate
+	for _, output := range outputs {
+		changed = merger.MergeEvent(ctx, changed, output)
+	}
+	return changed
+}
+
+// applies reports whether the transform of a stage applies to the update
+func applies(stage Stage, partialUpdate, fullModel *model.Event) bool {
+	eventTypeIDs := stage.EventTypeIDs
+	if scoped, ok := stage.Transform.(Scoped); ok && len(eventTypeIDs) == 0 {
+		eventTypeIDs = scoped.EventTypeIDs()
+	}
+	if len(eventTypeIDs) > 0 && !slices.Contains(eventTypeIDs, fullModel.GetEventTypeID().GetValue()) {
+		return false
+	}
+
+	return stage.When == nil || stage.When(partialUpdate, fullModel)
+}
+
+// runStage runs the transform of a stage, retrying it with an exponential backoff as allowed by its error policy
+func runStage(ctx context.Context, stage Stage, partialUpdate, fullModel *model.Event) (*model.Event, error) {
+	attempts := 1
+	if stage.OnError == ErrorPolicyRetry {
+		attempts += stage.Retries
+	}
+	backoff := stage.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			}
+			backoff = min(2*backoff, max(MaxBackoff, stage.Backoff))
+		}
+
+		var upd *model.Event
+		upd, err = stage.Transform.TransformEvent(ctx, partialUpdate, fullModel)
+		if err == nil {
+			return upd, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, err
+}
+
+// failedDependency returns the name of a dependency of the stage that failed, if any
+func failedDependency(stage Stage, failed map[string]bool) string {
+	for _, dep := range stage.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
@@ -0,0 +1,268 @@
+package transforms_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// fakeTransform appends its name to the event name and fails the first failures times it runs
+type fakeTransform struct {
+	name     string
+	failures int
+	runs     int
+}
+
+func (t *fakeTransform) TransformEvent(_ context.Context, _, fullModel *model.Event) (*model.Event, error) {
+	t.runs++
+	if t.runs <= t.failures {
+		return nil, errors.New("transform_failed")
+	}
+	return &model.Event{
+		ID:   fullModel.GetID(),
+		Name: &model.OptionalString{Value: fullModel.GetName().GetValue() + t.name},
+	}, nil
+}
+
+func (t *fakeTransform) GetName() string {
+	return t.name
+}
+
+func TestNewPipeline_OrdersByDependencies(t *testing.T) {
+	pipeline, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "C"}, DependsOn: []string{"B"}},
+		transforms.Stage{Transform: &fakeTransform{name: "A"}},
+		transforms.Stage{Transform: &fakeTransform{name: "B"}, DependsOn: []string{"A"}},
+		transforms.Stage{Transform: &fakeTransform{name: "D"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := pipeline.Names(), []string{"A", "B", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected order %v, got %v", want, got)
+	}
+
+	out, failures, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(),
+		&model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("unexpected failures %v, error %v", failures, err)
+	}
+	if out.GetName().GetValue() != "ABCD" {
+		t.Fatalf("expected transforms to run in order, got %q", out.GetName().GetValue())
+	}
+}
+
+func TestNewPipeline_InvalidDependencies(t *testing.T) {
+	_, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "A"}, DependsOn: []string{"B"}},
+		transforms.Stage{Transform: &fakeTransform{name: "B"}, DependsOn: []string{"A"}},
+	)
+	if err == nil {
+		t.Fatalf("expected an error for a dependency cycle")
+	}
+
+	_, err = transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "A"}, DependsOn: []string{"missing"}},
+	)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown dependency")
+	}
+}
+
+func TestPipeline_ErrorPolicies(t *testing.T) {
+	pipeline, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "A", failures: 1}},
+		transforms.Stage{Transform: &fakeTransform{name: "B"}, DependsOn: []string{"A"}},
+		transforms.Stage{Transform: &fakeTransform{name: "C", failures: 2}, OnError: transforms.ErrorPolicyRetry, Retries: 2},
+		transforms.Stage{Transform: &fakeTransform{name: "D"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, failures, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(),
+		&model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetName().GetValue() != "CD" {
+		t.Fatalf("expected A and B to be skipped, got %q", out.GetName().GetValue())
+	}
+	if len(failures) != 2 || failures[0].Transform != "A" || failures[1].Transform != "B" ||
+		!errors.Is(failures[1].Err, transforms.ErrDependencyFailed) {
+		t.Fatalf("unexpected failures %+v", failures)
+	}
+
+	failing, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "A", failures: 1}, OnError: transforms.ErrorPolicyFail},
+		transforms.Stage{Transform: &fakeTransform{name: "B"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, failures, err = failing.Run(context.Background(), merger.NewInlineMergerClient(),
+		&model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if err == nil || len(failures) != 1 {
+		t.Fatalf("expected the update to fail, got failures %v and error %v", failures, err)
+	}
+
+	// a transform that must not be skipped fails the update when its dependency fails
+	dependent, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &fakeTransform{name: "A", failures: 1}},
+		transforms.Stage{Transform: &fakeTransform{name: "B"}, DependsOn: []string{"A"}, OnError: transforms.ErrorPolicyFail},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, failures, err = dependent.Run(context.Background(), merger.NewInlineMergerClient(),
+		&model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if !errors.Is(err, transforms.ErrDependencyFailed) || len(failures) != 2 {
+		t.Fatalf("expected the update to fail, got failures %v and error %v", failures, err)
+	}
+}
+
+func TestPipeline_RetryBackoff(t *testing.T) {
+	retried := &fakeTransform{name: "A", failures: 2}
+	pipeline, err := transforms.NewPipeline(transforms.Stage{
+		Transform: retried,
+		OnError:   transforms.ErrorPolicyRetry,
+		Retries:   2,
+		Backoff:   10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	out, failures, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(),
+		&model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if err != nil || len(failures) != 0 || out.GetName().GetValue() != "A" {
+		t.Fatalf("expected the retries to succeed, got failures %v and error %v", failures, err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected to back off 10ms then 20ms between retries, took %v", elapsed)
+	}
+
+	// the backoff stops when the update is cancelled
+	waiting, err := transforms.NewPipeline(transforms.Stage{
+		Transform: &fakeTransform{name: "B", failures: 2},
+		OnError:   transforms.ErrorPolicyRetry,
+		Retries:   2,
+		Backoff:   time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, failures, err = waiting.Run(ctx, merger.NewInlineMergerClient(), &model.Event{ID: "evt-1"},
+		&model.Event{ID: "evt-1"})
+	if err != nil || len(failures) != 1 || time.Since(start) > time.Minute {
+		t.Fatalf("expected the stage to be skipped once cancelled, got failures %v and error %v", failures, err)
+	}
+}
+
+// scopedTransform is a fakeTransform that only applies to soccer
+type scopedTransform struct {
+	fakeTransform
+}
+
+func (t *scopedTransform) EventTypeIDs() []string {
+	return []string{"soccer"}
+}
+
+func TestPipeline_RoutesByEventType(t *testing.T) {
+	pipeline, err := transforms.NewPipeline(
+		transforms.Stage{Transform: &scopedTransform{fakeTransform{name: "S"}}},
+		transforms.Stage{Transform: &fakeTransform{name: "R"}, EventTypeIDs: []string{"horse_racing"}},
+		transforms.Stage{
+			Transform: &fakeTransform{name: "P"},
+			When: func(partialUpdate, _ *model.Event) bool {
+				return partialUpdate.GetName() != nil
+			},
+		},
+		transforms.Stage{Transform: &fakeTransform{name: "A"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	soccer := &model.Event{ID: "evt-1", EventTypeID: &model.OptionalString{Value: "soccer"}}
+	out, _, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(), soccer, soccer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetName().GetValue() != "SA" {
+		t.Fatalf("expected only S and A to run, got %q", out.GetName().GetValue())
+	}
+
+	racing := &model.Event{
+		ID:          "evt-2",
+		Name:        &model.OptionalString{Value: ""},
+		EventTypeID: &model.OptionalString{Value: "horse_racing"},
+	}
+	out, _, err = pipeline.Run(context.Background(), merger.NewInlineMergerClient(), racing, racing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetName().GetValue() != "RPA" {
+		t.Fatalf("expected R, P and A to run, got %q", out.GetName().GetValue())
+	}
+
+	want := []transforms.StageStats{
+		{Transform: "S", Runs: 1, Skipped: 1},
+		{Transform: "R", Runs: 1, Skipped: 1},
+		{Transform: "P", Runs: 1, Skipped: 1},
+		{Transform: "A", Runs: 2},
+	}
+	if got := pipeline.Stats(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected stats %+v, got %+v", want, got)
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for name, want := range map[string]transforms.ErrorPolicy{
+		"":      transforms.ErrorPolicySkip,
+		"skip":  transforms.ErrorPolicySkip,
+		"fail":  transforms.ErrorPolicyFail,
+		"retry": transforms.ErrorPolicyRetry,
+	} {
+		got, err := transforms.ParseErrorPolicy(name)
+		if err != nil || got != want {
+			t.Fatalf("expected %q to parse to %v, got %v (error %v)", name, want, got, err)
+		}
+	}
+
+	if _, err := transforms.ParseErrorPolicy("ignore"); err == nil {
+		t.Fatalf("expected an error for an unknown policy")
+	}
+}
+
+func TestWrap_KeepsNameAndScope(t *testing.T) {
+	scoped := &scopedTransform{fakeTransform{name: "S"}}
+	calls := 0
+	wrapped := transforms.Wrap(scoped, func(ctx context.Context, partialUpdate, fullModel *model.Event) (
+		*model.Event, error,
+	) {
+		calls++
+		return scoped.TransformEvent(ctx, partialUpdate, fullModel)
+	})
+	if wrapped.GetName() != "S" {
+		t.Fatalf("expected the name of the transform, got %q", wrapped.GetName())
+	}
+
+	pipeline := transforms.NewSequentialPipeline(wrapped)
+	racing := &model.Event{ID: "evt-1", EventTypeID: &model.OptionalString{Value: "horse_racing"}}
+	if _, _, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(), racing, racing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected the wrapped transform to keep its event types")
+	}
+}
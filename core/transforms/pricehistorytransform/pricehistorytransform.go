@@ -0,0 +1,125 @@
+// Package pricehistorytransform supplies a priceHistoryTransformClient
+package pricehistorytransform
+
+import (
+	"context"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// DefaultMaxChanges is the number of price changes kept per selection when none is configured
+const DefaultMaxChanges = 10
+
+// Config holds how much history is kept per selection
+type Config struct {
+	MaxChanges int              // number of most recent changes kept, defaults to DefaultMaxChanges
+	Now        func() time.Time // clock used to timestamp changes, defaults to time.Now
+}
+
+type priceHistoryTransformClient struct {
+	maxChanges int
+	now        func() time.Time
+}
+
+// NewPriceHistoryTransformClient creates a new PriceHistory transform client
+func NewPriceHistoryTransformClient(config Config) transforms.TransformClient {
+	t := &priceHistoryTransformClient{maxChanges: config.MaxChanges, now: config.Now}
+	if t.maxChanges <= 0 {
+		t.maxChanges = DefaultMaxChanges
+	}
+	if t.now == nil {
+		t.now = time.Now
+	}
+
+	return t
+}
+
+// TransformEvent records the price of every selection whose price changed in this update
+func (t *priceHistoryTransformClient) TransformEvent(_ context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	var outDelta *model.Event
+
+	changed := map[string]map[string]bool{}
+	for _, market := range partialUpdate.GetMarkets() {
+		for _, selection := range market.GetSelections() {
+			if selection.GetPrice() == nil {
+				continue
+			}
+			if changed[market.GetID()] == nil {
+				changed[market.GetID()] = map[string]bool{}
+			}
+			changed[market.GetID()][selection.GetID()] = true
+		}
+	}
+	if len(changed) == 0 {
+		return outDelta, nil // no price changed on this update
+	}
+
+	timestamp := t.now().UnixNano()
+	var markets []*model.Market
+	for _, market := range fullModel.GetMarkets() {
+		var selections []*model.Selection
+		for _, selection := range market.GetSelections() {
+			if !changed[market.GetID()][selection.GetID()] {
+				continue
+			}
+			if history := t.record(selection, timestamp); history != nil {
+				selections = append(selections, &model.Selection{ID: selection.GetID(), PriceHistory: history})
+			}
+		}
+		if len(selections) > 0 {
+			markets = append(markets, &model.Market{ID: market.GetID(), Selections: selections})
+		}
+	}
+
+	if len(markets) == 0 {
+		return outDelta, nil
+	}
+
+	outDelta = &model.Event{
+		ID:      partialUpdate.ID,
+		Markets: markets,
+	}
+
+	return outDelta, nil
+}
+
+// record returns the history of the selection with its current price appended, or nil if the price did not move.
+// The price is read from the full model so it reflects the transforms that ran earlier, e.g ladder snapping.
+func (t *priceHistoryTransformClient) record(selection *model.Selection, timestamp int64) *model.PriceHistory {
+	price := selection.GetPrice()
+	if price == nil || price.GetDeleted() {
+		return nil // removed prices are not part of the history
+	}
+
+	existing := selection.GetPriceHistory()
+	changes := existing.GetChanges()
+	if len(changes) > 0 && changes[len(changes)-1].GetPrice() == price.GetValue() {
+		return nil
+	}
+
+	history := &model.PriceHistory{
+		OpeningPrice: existing.GetOpeningPrice(),
+		Changes:      make([]*model.PriceChange, 0, t.maxChanges),
+	}
+	if history.OpeningPrice == nil {
+		history.OpeningPrice = &model.OptionalDouble{Value: price.GetValue()}
+	}
+	if len(changes) > 0 {
+		history.PreviousPrice = &model.OptionalDouble{Value: changes[len(changes)-1].GetPrice()}
+	}
+	if len(changes) >= t.maxChanges {
+		changes = changes[len(changes)-t.maxChanges+1:]
+	}
+	history.Changes = append(history.Changes, changes...)
+	history.Changes = append(history.Changes, &model.PriceChange{Price: price.GetValue(), Timestamp: timestamp})
+
+	return history
+}
+
+func (t *priceHistoryTransformClient) GetName() string {
+	return "PriceHistoryTransform"
+}
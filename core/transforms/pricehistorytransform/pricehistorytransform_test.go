@@ -0,0 +1,115 @@
+package pricehistorytransform_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/pricehistorytransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func pricedEvent(id string, price float64, history *model.PriceHistory) *model.Event {
+	return &model.Event{
+		ID: id,
+		Markets: []*model.Market{
+			{
+				ID: "m1",
+				Selections: []*model.Selection{
+					{ID: "s1", Price: &model.OptionalDouble{Value: price}, PriceHistory: history},
+				},
+			},
+		},
+	}
+}
+
+func fixedClock() time.Time {
+	return time.Unix(0, 1000)
+}
+
+func TestTransformEvent_SkipsWhenNoPricesUpdated(t *testing.T) {
+	client := pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{Now: fixedClock})
+
+	partial := &model.Event{ID: "evt-1", Name: &model.OptionalString{Value: "Renamed"}}
+
+	out, err := client.TransformEvent(context.Background(), partial, pricedEvent("evt-1", 2, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when no prices updated, got %#v", out)
+	}
+}
+
+func TestTransformEvent_RecordsOpeningPrice(t *testing.T) {
+	client := pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{Now: fixedClock})
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-2", 2, nil), pricedEvent("evt-2", 2, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected output event, got nil")
+	}
+
+	history := out.Markets[0].Selections[0].GetPriceHistory()
+	if history.GetOpeningPrice().GetValue() != 2 || history.GetPreviousPrice() != nil {
+		t.Fatalf("expected opening price only, got %+v", history)
+	}
+	if len(history.GetChanges()) != 1 || history.GetChanges()[0].GetTimestamp() != 1000 {
+		t.Fatalf("expected a single change, got %+v", history.GetChanges())
+	}
+}
+
+func TestTransformEvent_KeepsMostRecentChanges(t *testing.T) {
+	client := pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{
+		MaxChanges: 2,
+		Now:        fixedClock,
+	})
+
+	existing := &model.PriceHistory{
+		OpeningPrice:  &model.OptionalDouble{Value: 2},
+		PreviousPrice: &model.OptionalDouble{Value: 2},
+		Changes:       []*model.PriceChange{{Price: 2, Timestamp: 1}, {Price: 2.2, Timestamp: 2}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-3", 2.4, nil),
+		pricedEvent("evt-3", 2.4, existing))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history := out.Markets[0].Selections[0].GetPriceHistory()
+	if history.GetOpeningPrice().GetValue() != 2 || history.GetPreviousPrice().GetValue() != 2.2 {
+		t.Fatalf("expected opening and previous prices, got %+v", history)
+	}
+	changes := history.GetChanges()
+	if len(changes) != 2 || changes[0].GetPrice() != 2.2 || changes[1].GetPrice() != 2.4 {
+		t.Fatalf("expected the two most recent changes, got %+v", changes)
+	}
+}
+
+func TestTransformEvent_SkipsUnchangedPrice(t *testing.T) {
+	client := pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{Now: fixedClock})
+
+	existing := &model.PriceHistory{
+		OpeningPrice: &model.OptionalDouble{Value: 2},
+		Changes:      []*model.PriceChange{{Price: 2, Timestamp: 1}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), pricedEvent("evt-4", 2, nil),
+		pricedEvent("evt-4", 2, existing))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when price did not move, got %#v", out)
+	}
+}
+
+func TestGetName(t *testing.T) {
+	client := pricehistorytransform.NewPriceHistoryTransformClient(pricehistorytransform.Config{})
+	if got := client.GetName(); got != "PriceHistoryTransform" {
+		t.Fatalf("expected name %q, got %q", "PriceHistoryTransform", got)
+	}
+}
@@ -0,0 +1,64 @@
+package remotetransform
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without calling the remote transform, while its circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit_open")
+
+// breaker opens after a number of consecutive failures so a struggling remote transform is not called on every
+// update. Once the cooldown has passed a single call is let through, closing the breaker again if it succeeds.
+type breaker struct {
+	mtx       sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// allow reports whether a call may be made
+func (b *breaker) allow() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record records the outcome of a call
+func (b *breaker) record(err error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// abandon records a call whose outcome says nothing about the remote transform, such as one cancelled with its update.
+// A probe is released so the next call probes again, instead of the breaker staying open for good.
+func (b *breaker) abandon() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,197 @@
+// Package remotetransform supplies a TransformClient calling a transform that runs out of process over gRPC, and
+// the server side to expose a TransformClient as such a transform
+package remotetransform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/certs"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Defaults used for unset Config values
+const (
+	DefaultTimeout          = 500 * time.Millisecond
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 10 * time.Second
+)
+
+// Config configures a remote transform and the pipeline stage it runs in
+type Config struct {
+	Name             string             `yaml:"name"`
+	Address          string             `yaml:"address"`
+	Timeout          time.Duration      `yaml:"timeout"`          // deadline of each call
+	FailureThreshold int                `yaml:"failureThreshold"` // consecutive failures opening the circuit breaker
+	Cooldown         time.Duration      `yaml:"cooldown"`         // time the circuit breaker stays open
+	DependsOn        []string           `yaml:"dependsOn"`
+	OnError          string             `yaml:"onError"` // skip, fail or retry
+	Retries          int                `yaml:"retries"`
+	Backoff          time.Duration      `yaml:"backoff"` // delay of the first retry, doubled up to transforms.MaxBackoff
+	EventTypeIDs     []string           `yaml:"eventTypeIDs"`
+	TLS              certs.ClientConfig `yaml:"tls"` // connect over TLS, plaintext when unset
+
+	Now func() time.Time `yaml:"-"` // used by the circuit breaker, defaults to time.Now
+}
+
+// file is the layout of a remote transforms file
+type file struct {
+	Transforms []Config `yaml:"transforms"`
+}
+
+// LoadConfigs reads the remote transforms of a YAML file
+func LoadConfigs(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote transforms: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse remote transforms %v: %w", path, err)
+	}
+	for i := range f.Transforms {
+		if err := f.Transforms[i].Validate(); err != nil {
+			return nil, err
+		}
+		// certificate files are relative to the remote transforms file
+		tls := &f.Transforms[i].TLS
+		for _, file := range []*string{&tls.CAFile, &tls.CertFile, &tls.KeyFile} {
+			if *file != "" && !filepath.IsAbs(*file) {
+				*file = filepath.Join(filepath.Dir(path), *file)
+			}
+		}
+	}
+	return f.Transforms, nil
+}
+
+// Validate checks that a remote transform can be used
+func (c Config) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("remote transform has no name")
+	}
+	if c.Address == "" {
+		return fmt.Errorf("remote transform %v has no address", c.Name)
+	}
+	if _, err := transforms.ParseErrorPolicy(c.OnError); err != nil {
+		return fmt.Errorf("remote transform %v: %w", c.Name, err)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("remote transform %v: tls certFile and keyFile must be set together", c.Name)
+	}
+	return nil
+}
+
+// Stage returns the pipeline stage running a transform as configured
+func (c Config) Stage(transform transforms.TransformClient) (transforms.Stage, error) {
+	onError, err := transforms.ParseErrorPolicy(c.OnError)
+	if err != nil {
+		return transforms.Stage{}, fmt.Errorf("remote transform %v: %w", c.Name, err)
+	}
+	return transforms.Stage{
+		Transform:    transform,
+		DependsOn:    c.DependsOn,
+		OnError:      onError,
+		Retries:      c.Retries,
+		Backoff:      c.Backoff,
+		EventTypeIDs: c.EventTypeIDs,
+	}, nil
+}
+
+type remoteTransformClient struct {
+	name    string
+	client  TransformClient
+	timeout time.Duration
+	breaker *breaker
+}
+
+// NewRemoteTransformClient creates a new Remote transform client calling the Transform service over a connection
+func NewRemoteTransformClient(conn grpc.ClientConnInterface, config Config) transforms.TransformClient {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultFailureThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCooldown
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &remoteTransformClient{
+		name:    config.Name,
+		client:  NewTransformClient(conn),
+		timeout: config.Timeout,
+		breaker: &breaker{threshold: config.FailureThreshold, cooldown: config.Cooldown, now: config.Now},
+	}
+}
+
+// Dial connects to a remote transform, the connection has to be closed once the transform is no longer used
+func Dial(config Config) (transforms.TransformClient, *grpc.ClientConn, error) {
+	creds, err := config.TLS.Credentials()
+	if err != nil {
+		return nil, nil, fmt.Errorf("remote transform %v: %w", config.Name, err)
+	}
+	conn, err := grpc.NewClient(config.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to remote transform %v: %w", config.Name, err)
+	}
+	return NewRemoteTransformClient(conn, config), conn, nil
+}
+
+// TransformEvent calls the remote transform, failing straight away while its circuit breaker is open
+func (t *remoteTransformClient) TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	if !t.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	resp, err := t.client.TransformEvent(callCtx, &TransformRequest{PartialUpdate: partialUpdate, FullModel: fullModel})
+	if ctx.Err() == nil {
+		t.breaker.record(err)
+	} else {
+		// the update being cancelled says nothing about the health of the remote transform
+		t.breaker.abandon()
+	}
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("transform", t.name).Warn("remote_transform_failed")
+		return nil, err
+	}
+
+	return resp.GetDelta(), nil
+}
+
+func (t *remoteTransformClient) GetName() string {
+	return t.name
+}
+
+type server struct {
+	transform transforms.TransformClient
+}
+
+// NewServer exposes a TransformClient as a Transform service, register it with RegisterTransformServer
+func NewServer(transform transforms.TransformClient) TransformServer {
+	return &server{transform: transform}
+}
+
+func (s *server) TransformEvent(ctx context.Context, req *TransformRequest) (*TransformResponse, error) {
+	delta, err := s.transform.TransformEvent(ctx, req.GetPartialUpdate(), req.GetFullModel())
+	if err != nil {
+		return nil, err
+	}
+	return &TransformResponse{Delta: delta}, nil
+}
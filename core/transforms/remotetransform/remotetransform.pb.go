@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.33.4
+// source: remotetransform.proto
+
+//go:build !protoopaque
+
+package remotetransform
+
+import (
+	model "git.neds.sh/technology/pricekinetics/tools/codetest/model"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransformRequest struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	PartialUpdate *model.Event           `protobuf:"bytes,1,opt,name=PartialUpdate,proto3" json:"PartialUpdate,omitempty"`
+	FullModel     *model.Event           `protobuf:"bytes,2,opt,name=FullModel,proto3" json:"FullModel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformRequest) Reset() {
+	*x = TransformRequest{}
+	mi := &file_remotetransform_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformRequest) ProtoMessage() {}
+
+func (x *TransformRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotetransform_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TransformRequest) GetPartialUpdate() *model.Event {
+	if x != nil {
+		return x.PartialUpdate
+	}
+	return nil
+}
+
+func (x *TransformRequest) GetFullModel() *model.Event {
+	if x != nil {
+		return x.FullModel
+	}
+	return nil
+}
+
+func (x *TransformRequest) SetPartialUpdate(v *model.Event) {
+	x.PartialUpdate = v
+}
+
+func (x *TransformRequest) SetFullModel(v *model.Event) {
+	x.FullModel = v
+}
+
+func (x *TransformRequest) HasPartialUpdate() bool {
+	if x == nil {
+		return false
+	}
+	return x.PartialUpdate != nil
+}
+
+func (x *TransformRequest) HasFullModel() bool {
+	if x == nil {
+		return false
+	}
+	return x.FullModel != nil
+}
+
+func (x *TransformRequest) ClearPartialUpdate() {
+	x.PartialUpdate = nil
+}
+
+func (x *TransformRequest) ClearFullModel() {
+	x.FullModel = nil
+}
+
+type TransformRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	PartialUpdate *model.Event
+	FullModel     *model.Event
+}
+
+func (b0 TransformRequest_builder) Build() *TransformRequest {
+	m0 := &TransformRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.PartialUpdate = b.PartialUpdate
+	x.FullModel = b.FullModel
+	return m0
+}
+
+type TransformResponse struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	Delta         *model.Event           `protobuf:"bytes,1,opt,name=Delta,proto3" json:"Delta,omitempty"` // changes to merge into the full model, empty when the transform has nothing to change
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformResponse) Reset() {
+	*x = TransformResponse{}
+	mi := &file_remotetransform_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformResponse) ProtoMessage() {}
+
+func (x *TransformResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotetransform_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TransformResponse) GetDelta() *model.Event {
+	if x != nil {
+		return x.Delta
+	}
+	return nil
+}
+
+func (x *TransformResponse) SetDelta(v *model.Event) {
+	x.Delta = v
+}
+
+func (x *TransformResponse) HasDelta() bool {
+	if x == nil {
+		return false
+	}
+	return x.Delta != nil
+}
+
+func (x *TransformResponse) ClearDelta() {
+	x.Delta = nil
+}
+
+type TransformResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Delta *model.Event
+}
+
+func (b0 TransformResponse_builder) Build() *TransformResponse {
+	m0 := &TransformResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.Delta = b.Delta
+	return m0
+}
+
+var File_remotetransform_proto protoreflect.FileDescriptor
+
+const file_remotetransform_proto_rawDesc = "" +
+	"\n" +
+	"\x15remotetransform.proto\x12\x0fremotetransform\x1a\x11model/event.proto\"r\n" +
+	"\x10TransformRequest\x122\n" +
+	"\rPartialUpdate\x18\x01 \x01(\v2\f.model.EventR\rPartialUpdate\x12*\n" +
+	"\tFullModel\x18\x02 \x01(\v2\f.model.EventR\tFullModel\"7\n" +
+	"\x11TransformResponse\x12\"\n" +
+	"\x05Delta\x18\x01 \x01(\v2\f.model.EventR\x05Delta2f\n" +
+	"\tTransform\x12Y\n" +
+	"\x0eTransformEvent\x12!.remotetransform.TransformRequest\x1a\".remotetransform.TransformResponse\"\x00BUZSgit.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/remotetransformb\x06proto3"
+
+var file_remotetransform_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_remotetransform_proto_goTypes = []any{
+	(*TransformRequest)(nil),  // 0: remotetransform.TransformRequest
+	(*TransformResponse)(nil), // 1: remotetransform.TransformResponse
+	(*model.Event)(nil),       // 2: model.Event
+}
+var file_remotetransform_proto_depIdxs = []int32{
+	2, // 0: remotetransform.TransformRequest.PartialUpdate:type_name -> model.Event
+	2, // 1: remotetransform.TransformRequest.FullModel:type_name -> model.Event
+	2, // 2: remotetransform.TransformResponse.Delta:type_name -> model.Event
+	0, // 3: remotetransform.Transform.TransformEvent:input_type -> remotetransform.TransformRequest
+	1, // 4: remotetransform.Transform.TransformEvent:output_type -> remotetransform.TransformResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_remotetransform_proto_init() }
+func file_remotetransform_proto_init() {
+	if File_remotetransform_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_remotetransform_proto_rawDesc), len(file_remotetransform_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remotetransform_proto_goTypes,
+		DependencyIndexes: file_remotetransform_proto_depIdxs,
+		MessageInfos:      file_remotetransform_proto_msgTypes,
+	}.Build()
+	File_remotetransform_proto = out.File
+	file_remotetransform_proto_goTypes = nil
+	file_remotetransform_proto_depIdxs = nil
+}
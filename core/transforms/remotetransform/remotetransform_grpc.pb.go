@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v6.33.4
+// source: remotetransform.proto
+
+package remotetransform
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Transform_TransformEvent_FullMethodName = "/remotetransform.Transform/TransformEvent"
+)
+
+// TransformClient is the client API for Transform service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TransformClient interface {
+	// TransformEvent runs an out-of-process transform on an update and returns the changes it makes
+	TransformEvent(ctx context.Context, in *TransformRequest, opts ...grpc.CallOption) (*TransformResponse, error)
+}
+
+type transformClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransformClient(cc grpc.ClientConnInterface) TransformClient {
+	return &transformClient{cc}
+}
+
+func (c *transformClient) TransformEvent(ctx context.Context, in *TransformRequest, opts ...grpc.CallOption) (*TransformResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransformResponse)
+	err := c.cc.Invoke(ctx, Transform_TransformEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransformServer is the server API for Transform service.
+// All implementations should embed UnimplementedTransformServer
+// for forward compatibility.
+type TransformServer interface {
+	// TransformEvent runs an out-of-process transform on an update and returns the changes it makes
+	TransformEvent(context.Context, *TransformRequest) (*TransformResponse, error)
+}
+
+// UnimplementedTransformServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTransformServer struct{}
+
+func (UnimplementedTransformServer) TransformEvent(context.Context, *TransformRequest) (*TransformResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TransformEvent not implemented")
+}
+func (UnimplementedTransformServer) testEmbeddedByValue() {}
+
+// UnsafeTransformServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransformServer will
+// result in compilation errors.
+type UnsafeTransformServer interface {
+	mustEmbedUnimplementedTransformServer()
+}
+
+func RegisterTransformServer(s grpc.ServiceRegistrar, srv TransformServer) {
+	// If the following call panics, it indicates UnimplementedTransformServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Transform_ServiceDesc, srv)
+}
+
+func _Transform_TransformEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransformRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformServer).TransformEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transform_TransformEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformServer).TransformEvent(ctx, req.(*TransformRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Transform_ServiceDesc is the grpc.ServiceDesc for Transform service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Transform_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "remotetransform.Transform",
+	HandlerType: (*TransformServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TransformEvent",
+			Handler:    _Transform_TransformEvent_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "remotetransform.proto",
+}
@@ -0,0 +1,225 @@
+package remotetransform_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/remotetransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// fakeTransform names the event, or fails while err is set, after waiting for delay
+type fakeTransform struct {
+	delay time.Duration
+	err   error
+	calls int
+}
+
+func (t *fakeTransform) TransformEvent(ctx context.Context, _, fullModel *model.Event) (*model.Event, error) {
+	t.calls++
+	select {
+	case <-time.After(t.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &model.Event{ID: fullModel.GetID(), Name: &model.OptionalString{Value: "Remote"}}, nil
+}
+
+func (t *fakeTransform) GetName() string {
+	return "Fake"
+}
+
+// serve runs the transform as a Transform service and returns a connection to it
+func serve(t *testing.T, transform transforms.TransformClient) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	remotetransform.RegisterTransformServer(srv, remotetransform.NewServer(transform))
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestTransformEvent_ReturnsDelta(t *testing.T) {
+	client := remotetransform.NewRemoteTransformClient(serve(t, &fakeTransform{}), remotetransform.Config{Name: "Remote"})
+
+	event := &model.Event{ID: "evt-1"}
+	out, err := client.TransformEvent(context.Background(), event, event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetID() != "evt-1" || out.GetName().GetValue() != "Remote" {
+		t.Fatalf("unexpected delta %+v", out)
+	}
+	if client.GetName() != "Remote" {
+		t.Fatalf("expected name %q, got %q", "Remote", client.GetName())
+	}
+}
+
+func TestTransformEvent_Deadline(t *testing.T) {
+	conn := serve(t, &fakeTransform{delay: time.Second})
+	client := remotetransform.NewRemoteTransformClient(conn, remotetransform.Config{
+		Name:    "Remote",
+		Timeout: 20 * time.Millisecond,
+	})
+
+	event := &model.Event{ID: "evt-1"}
+	_, err := client.TransformEvent(context.Background(), event, event)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected the call to exceed its deadline, got %v", err)
+	}
+}
+
+func TestTransformEvent_CircuitBreaker(t *testing.T) {
+	transform := &fakeTransform{err: errors.New("remote_failure")}
+	now := time.Now()
+	client := remotetransform.NewRemoteTransformClient(serve(t, transform), remotetransform.Config{
+		Name:             "Remote",
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		Now:              func() time.Time { return now },
+	})
+
+	event := &model.Event{ID: "evt-1"}
+	for i := 0; i < 2; i++ {
+		if _, err := client.TransformEvent(context.Background(), event, event); err == nil {
+			t.Fatalf("expected the remote failure to be returned")
+		}
+	}
+
+	_, err := client.TransformEvent(context.Background(), event, event)
+	if !errors.Is(err, remotetransform.ErrCircuitOpen) || transform.calls != 2 {
+		t.Fatalf("expected the open circuit to stop calls, got %v after %d calls", err, transform.calls)
+	}
+
+	// after the cooldown a successful call closes the circuit
+	now = now.Add(time.Minute)
+	transform.err = nil
+	if _, err := client.TransformEvent(context.Background(), event, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.TransformEvent(context.Background(), event, event); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+}
+
+func TestTransformEvent_CircuitBreakerCancelledProbe(t *testing.T) {
+	transform := &fakeTransform{err: errors.New("remote_failure")}
+	now := time.Now()
+	client := remotetransform.NewRemoteTransformClient(serve(t, transform), remotetransform.Config{
+		Name:             "Remote",
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+		Now:              func() time.Time { return now },
+	})
+
+	event := &model.Event{ID: "evt-1"}
+	if _, err := client.TransformEvent(context.Background(), event, event); err == nil {
+		t.Fatalf("expected the remote failure to be returned")
+	}
+
+	// the probe after the cooldown is cancelled along with its update
+	now = now.Add(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.TransformEvent(ctx, event, event); err == nil {
+		t.Fatalf("expected the cancelled probe to fail")
+	}
+
+	// the cancelled probe is released so the next call probes again
+	transform.err = nil
+	if _, err := client.TransformEvent(context.Background(), event, event); err != nil {
+		t.Fatalf("expected a new probe to close the circuit, got %v", err)
+	}
+	if _, err := client.TransformEvent(context.Background(), event, event); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remote.yaml")
+	data := `transforms:
+  - name: RacingTransform
+    address: racing-transform:50051
+    timeout: 250ms
+    dependsOn: [LadderTransform]
+    onError: retry
+    retries: 2
+    backoff: 20ms
+    eventTypeIDs: [horse_racing]
+    tls:
+      caFile: ca.pem
+      certFile: /etc/core/client.crt
+      keyFile: /etc/core/client.key
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	configs, err := remotetransform.LoadConfigs(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configs) != 1 || configs[0].Timeout != 250*time.Millisecond {
+		t.Fatalf("unexpected configs %+v", configs)
+	}
+	if configs[0].TLS.CAFile != filepath.Join(filepath.Dir(path), "ca.pem") ||
+		configs[0].TLS.CertFile != "/etc/core/client.crt" {
+		t.Fatalf("expected certificate files relative to the config file, got %+v", configs[0].TLS)
+	}
+
+	stage, err := configs[0].Stage(&fakeTransform{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stage.OnError != transforms.ErrorPolicyRetry || stage.Retries != 2 || stage.Backoff != 20*time.Millisecond ||
+		stage.EventTypeIDs[0] != "horse_racing" {
+		t.Fatalf("unexpected stage %+v", stage)
+	}
+
+	if err := os.WriteFile(path, []byte("transforms:\n  - name: NoAddress\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := remotetransform.LoadConfigs(path); err == nil {
+		t.Fatalf("expected an error for a transform without an address")
+	}
+
+	data = "transforms:\n  - name: NoKey\n    address: localhost:1\n    tls: {certFile: client.crt}\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := remotetransform.LoadConfigs(path); err == nil {
+		t.Fatalf("expected an error for a client certificate without a key")
+	}
+}
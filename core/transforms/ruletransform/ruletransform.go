@@ -0,0 +1,198 @@
+// Package ruletransform supplies a ruleTransformClient
+package ruletransform
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/logging"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/rules"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+type ruleTransformClient struct {
+	rules []*rules.Rule
+}
+
+// NewRuleTransformClient creates a new Rule transform client applying compiled rules
+func NewRuleTransformClient(rules []*rules.Rule) transforms.TransformClient {
+	return &ruleTransformClient{rules: rules}
+}
+
+// NewRuleTransformClientFromFile creates a new Rule transform client applying the rules of a YAML file
+func NewRuleTransformClientFromFile(path string) (transforms.TransformClient, error) {
+	loaded, err := rules.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuleTransformClient(loaded), nil
+}
+
+// statused is an event, market or selection, whose betting status rules can set
+type statused interface {
+	GetBettingStatus() *model.OptionalBettingStatus
+	GetStatusRule() *model.OptionalString
+	GetStatusBeforeRule() *model.OptionalBettingStatus
+}
+
+// state is the betting status of an event, market or selection along with the rule that set it, if any
+type state struct {
+	status model.BettingStatus
+	rule   string
+	before model.BettingStatus // status restored once the rule no longer holds
+}
+
+func stateOf(s statused) state {
+	st := state{status: s.GetBettingStatus().GetValue()}
+	if rule := s.GetStatusRule(); rule != nil && !rule.GetDeleted() {
+		st.rule = rule.GetValue()
+		st.before = s.GetStatusBeforeRule().GetValue()
+	}
+	return st
+}
+
+// key identifies the event (when empty), a market or a selection of an event
+type key struct {
+	market    string
+	selection string
+}
+
+// delta collects the status changes made by the rules, keeping the order they are first made in
+type delta struct {
+	states map[key]state
+	order  []key
+}
+
+// apply records the change a rule makes to an event, market or selection, as changed by the rules before it. A
+// status set by the rule is restored once it no longer holds, unless something else changed the status meanwhile.
+func (d *delta) apply(logger *logrus.Entry, rule *rules.Rule, k key, s statused, matched bool) {
+	current, ok := d.states[k]
+	if !ok {
+		current = stateOf(s)
+	}
+
+	switch {
+	case matched && current.status != rule.Status():
+		logger.Info("rule matched")
+		d.set(k, state{status: rule.Status(), rule: rule.Name, before: current.status})
+	case !matched && current.rule == rule.Name && current.status == rule.Status():
+		logger.Info("rule no longer matches")
+		d.set(k, state{status: current.before})
+	case !matched && current.rule == rule.Name:
+		d.set(k, state{status: current.status})
+	}
+}
+
+func (d *delta) set(k key, st state) {
+	if _, ok := d.states[k]; !ok {
+		d.order = append(d.order, k)
+	}
+	d.states[k] = st
+}
+
+// fields returns the status fields of a state
+func (st state) fields() (*model.OptionalBettingStatus, *model.OptionalString, *model.OptionalBettingStatus) {
+	status := &model.OptionalBettingStatus{Value: st.status}
+	if st.rule == "" {
+		return status, &model.OptionalString{Deleted: true}, &model.OptionalBettingStatus{Deleted: true}
+	}
+	return status, &model.OptionalString{Value: st.rule}, &model.OptionalBettingStatus{Value: st.before}
+}
+
+// event returns the changes of the delta as a partial event
+func (d *delta) event(id string) *model.Event {
+	out := &model.Event{ID: id}
+	markets := map[string]*model.Market{}
+	market := func(id string) *model.Market {
+		if m, ok := markets[id]; ok {
+			return m
+		}
+		m := &model.Market{ID: id}
+		markets[id] = m
+		out.Markets = append(out.Markets, m)
+		return m
+	}
+
+	for _, k := range d.order {
+		st := d.states[k]
+		switch {
+		case k.market == "":
+			out.BettingStatus, out.StatusRule, out.StatusBeforeRule = st.fields()
+		case k.selection == "":
+			m := market(k.market)
+			m.BettingStatus, m.StatusRule, m.StatusBeforeRule = st.fields()
+		default:
+			selection := &model.Selection{ID: k.selection}
+			selection.BettingStatus, selection.StatusRule, selection.StatusBeforeRule = st.fields()
+			m := market(k.market)
+			m.Selections = append(m.Selections, selection)
+		}
+	}
+	return out
+}
+
+// TransformEvent evaluates every rule against the full model and sets the status of whatever they match, restoring
+// the statuses they set where they no longer hold. A rule that fails to evaluate is reported and skipped, the other
+// rules still apply.
+func (t *ruleTransformClient) TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	var outDelta *model.Event
+	d := &delta{states: map[key]state{}}
+
+	for _, rule := range t.rules {
+		if err := apply(logging.FromContext(ctx), d, rule, fullModel); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("rule", rule.Name).Error("rule_failed")
+		}
+	}
+
+	if len(d.order) == 0 {
+		return outDelta, nil
+	}
+
+	outDelta = d.event(partialUpdate.ID)
+
+	return outDelta, nil
+}
+
+// apply evaluates a rule on every part of the event in its scope, recording status changes in the delta
+func apply(logger *logrus.Entry, d *delta, rule *rules.Rule, event *model.Event) error {
+	logger = logger.WithField("rule", rule.Name)
+
+	if rule.Scope == rules.ScopeEvent {
+		matched, err := rule.Matches(event, nil, nil)
+		if err != nil {
+			return err
+		}
+		d.apply(logger.WithField("event", event.GetID()), rule, key{}, event, matched)
+		return nil
+	}
+
+	for _, market := range event.GetMarkets() {
+		if rule.Scope == rules.ScopeMarket {
+			matched, err := rule.Matches(event, market, nil)
+			if err != nil {
+				return err
+			}
+			d.apply(logger.WithField("market", market.GetID()), rule, key{market: market.GetID()}, market, matched)
+			continue
+		}
+
+		for _, selection := range market.GetSelections() {
+			matched, err := rule.Matches(event, market, selection)
+			if err != nil {
+				return err
+			}
+			d.apply(logger.WithField("selection", selection.GetID()), rule,
+				key{market: market.GetID(), selection: selection.GetID()}, selection, matched)
+		}
+	}
+
+	return nil
+}
+
+func (t *ruleTransformClient) GetName() string {
+	return "RuleTransform"
+}
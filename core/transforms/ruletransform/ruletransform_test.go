@@ -0,0 +1,166 @@
+package ruletransform_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/ruletransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+const testRules = `rules:
+  - name: suspend-line-when-h2h-short
+    scope: market
+    when: name == "Line" && any(event.markets, name == "Head to Head" && any(selections, price < 1.05))
+    set:
+      bettingStatus: BettingSuspended
+  - name: suspend-markets-without-open-selections
+    scope: market
+    when: count(selections, status == BettingOpen) == 0
+    set:
+      bettingStatus: BettingSuspended
+`
+
+func newClient(t *testing.T) interface {
+	TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (*model.Event, error)
+} {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(testRules), 0o600); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	client, err := ruletransform.NewRuleTransformClientFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return client
+}
+
+func openSelection(id string, price float64) *model.Selection {
+	return &model.Selection{
+		ID:            id,
+		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+		Price:         &model.OptionalDouble{Value: price},
+	}
+}
+
+func TestTransformEvent_NoRulesMatch(t *testing.T) {
+	client := newClient(t)
+
+	full := &model.Event{
+		ID: "evt-1",
+		Markets: []*model.Market{
+			{
+				ID:         "h2h",
+				Name:       &model.OptionalString{Value: "Head to Head"},
+				Selections: []*model.Selection{openSelection("home", 1.5)},
+			},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), &model.Event{ID: "evt-1"}, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when no rule matches, got %#v", out)
+	}
+}
+
+func TestTransformEvent_AppliesMatchingRules(t *testing.T) {
+	client := newClient(t)
+
+	full := &model.Event{
+		ID: "evt-2",
+		Markets: []*model.Market{
+			{
+				ID:         "h2h",
+				Name:       &model.OptionalString{Value: "Head to Head"},
+				Selections: []*model.Selection{openSelection("home", 1.04), openSelection("away", 13)},
+			},
+			{
+				ID:         "line",
+				Name:       &model.OptionalString{Value: "Line"},
+				Selections: []*model.Selection{openSelection("home", 1.9)},
+			},
+			{
+				ID:            "totals",
+				Name:          &model.OptionalString{Value: "Totals"},
+				BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+			},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), &model.Event{ID: "evt-2"}, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out.Markets) != 2 {
+		t.Fatalf("expected deltas for the line and totals markets, got %#v", out)
+	}
+	for i, id := range []string{"line", "totals"} {
+		market := out.Markets[i]
+		if market.GetID() != id || market.GetBettingStatus().GetValue() != model.BettingStatus_BettingSuspended {
+			t.Fatalf("expected market %v to be suspended, got %+v", id, market)
+		}
+		if market.GetStatusBeforeRule().GetValue() != full.Markets[i+1].GetBettingStatus().GetValue() {
+			t.Fatalf("expected market %v to record the status it had, got %+v", id, market)
+		}
+	}
+	if out.Markets[0].GetStatusRule().GetValue() != "suspend-line-when-h2h-short" {
+		t.Fatalf("expected the line market to record the rule that suspended it, got %+v", out.Markets[0])
+	}
+}
+
+func TestTransformEvent_RestoresStatusWhenRuleStopsHolding(t *testing.T) {
+	client := newClient(t)
+
+	suspendedBy := func(id, rule string, status model.BettingStatus) *model.Market {
+		return &model.Market{
+			ID:               id,
+			Name:             &model.OptionalString{Value: "Line"},
+			BettingStatus:    &model.OptionalBettingStatus{Value: status},
+			StatusRule:       &model.OptionalString{Value: rule},
+			StatusBeforeRule: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+			Selections:       []*model.Selection{openSelection("home", 1.9)},
+		}
+	}
+	full := &model.Event{
+		ID: "evt-3",
+		Markets: []*model.Market{
+			{
+				ID:         "h2h",
+				Name:       &model.OptionalString{Value: "Head to Head"},
+				Selections: []*model.Selection{openSelection("home", 1.2), openSelection("away", 4)},
+			},
+			// the H2H price recovered
+			suspendedBy("line", "suspend-line-when-h2h-short", model.BettingStatus_BettingSuspended),
+			// the feed closed the market since the rule suspended it
+			suspendedBy("closed", "suspend-line-when-h2h-short", model.BettingStatus_BettingClosed),
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), &model.Event{ID: "evt-3"}, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out.Markets) != 2 {
+		t.Fatalf("expected deltas for the line and closed markets, got %#v", out)
+	}
+	for i, want := range []model.BettingStatus{model.BettingStatus_BettingOpen, model.BettingStatus_BettingClosed} {
+		market := out.Markets[i]
+		if market.GetBettingStatus().GetValue() != want || !market.GetStatusRule().GetDeleted() ||
+			!market.GetStatusBeforeRule().GetDeleted() {
+			t.Fatalf("expected market %v to be %v without a rule, got %+v", market.GetID(), want, market)
+		}
+	}
+}
+
+func TestGetName(t *testing.T) {
+	client := ruletransform.NewRuleTransformClient(nil)
+	if got := client.GetName(); got != "RuleTransform" {
+		t.Fatalf("expected name %q, got %q", "RuleTransform", got)
+	}
+}
@@ -4,48 +4,89 @@ package sporttransform
 import (
 	"context"
 
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
-type sportTransformClient struct{}
+// Config holds the sport types mapping and how it is applied
+type Config struct {
+	Registry *sporttypes.Registry // defaults to the default sport types
+	// ReapplyNames overwrites sport names that differ from the mapping so a changed mapping is re-applied on the next
+	// update, otherwise names are only set when missing and names supplied by the feed are kept
+	ReapplyNames bool
+}
+
+type sportTransformClient struct {
+	registry     *sporttypes.Registry
+	reapplyNames bool
+}
 
-// NewSportTransformClient creates a new Sport transform client
+// NewSportTransformClient creates a new Sport transform client using the default sport types
 func NewSportTransformClient() transforms.TransformClient {
-	return &sportTransformClient{}
+	return NewSportTransformClientWithRegistry(sporttypes.NewRegistry(sporttypes.Defaults()...))
 }
 
-var sportTypeMap = map[string]string{
-	"soccer":       "Soccer",
-	"rugby_league": "Rugby League",
+// NewSportTransformClientWithRegistry creates a new Sport transform client mapping event types using the registry
+func NewSportTransformClientWithRegistry(registry *sporttypes.Registry) transforms.TransformClient {
+	return NewSportTransformClientWithConfig(Config{Registry: registry})
 }
 
-// TransformEvent performs sport specific transformation on the Event
+// NewSportTransformClientWithConfig creates a new Sport transform client from its config
+func NewSportTransformClientWithConfig(config Config) transforms.TransformClient {
+	registry := config.Registry
+	if registry == nil {
+		registry = sporttypes.NewRegistry(sporttypes.Defaults()...)
+	}
+	return &sportTransformClient{registry: registry, reapplyNames: config.ReapplyNames}
+}
+
+// TransformEvent performs sport specific transformation on the Event. The sport name, region and league are defaulted
+// from the mapping of the event type, the name follows the mapping when ReapplyNames is set.
 func (t *sportTransformClient) TransformEvent(_ context.Context, partialUpdate, fullModel *model.Event) (
 	*model.Event, error,
 ) {
 	var outDelta *model.Event
-	if partialUpdate.EventTypeID == nil {
-		return outDelta, nil // if the EventTypeID didnt update on this update skip processing the event
+
+	sportType, ok := t.registry.Get(fullModel.GetEventTypeID().GetValue())
+	if !ok {
+		return outDelta, nil // unknown sport type so don't change anything
 	}
 
-	if fullModel.GetSportData().GetName() != nil {
-		return outDelta, nil // no need to update the name if the name already set
+	sportData := fullModel.GetSportData()
+	delta := &model.SportEvent{}
+	changed := false
+	name := sportData.GetName()
+	if name == nil || name.GetDeleted() || (t.reapplyNames && name.GetValue() != sportType.Name) {
+		delta.Name = &model.OptionalString{Value: sportType.Name}
+		changed = true
+	}
+	if sportType.Region != "" && sportData.GetRegion() == nil {
+		delta.Region = &model.OptionalString{Value: sportType.Region}
+		changed = true
+	}
+	if sportType.League != "" && sportData.GetLeague() == nil {
+		delta.League = &model.OptionalString{Value: sportType.League}
+		changed = true
 	}
 
-	sportName := sportTypeMap[fullModel.GetEventTypeID().GetValue()]
-	if sportName == "" {
-		return outDelta, nil // unknown sport type so don't change anything
+	if !changed {
+		return outDelta, nil // no need to update the sport data if already set
 	}
 
 	outDelta = &model.Event{
 		ID:        partialUpdate.ID,
-		SportData: &model.SportEvent{Name: &model.OptionalString{Value: sportName}},
+		SportData: delta,
 	}
 
 	return outDelta, nil
 }
 
+// EventTypeIDs returns the event types the transform knows a sport name for
+func (t *sportTransformClient) EventTypeIDs() []string {
+	return t.registry.EventTypeIDs()
+}
+
 func (t *sportTransformClient) GetName() string {
 	return "SportsTransform"
 }
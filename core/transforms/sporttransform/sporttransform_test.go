@@ -2,19 +2,23 @@ package sporttransform_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/sporttypes"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/sporttransform"
 	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
 )
 
-func TestTransformEvent_SkipsWhenEventTypeNotUpdated(t *testing.T) {
+func TestTransformEvent_SkipsUnknownEventType(t *testing.T) {
 	client := sporttransform.NewSportTransformClient()
 
 	partial := &model.Event{ID: "evt-1"}
 	full := &model.Event{
-		ID:        "evt-1",
-		SportData: &model.SportEvent{},
+		ID:          "evt-1",
+		EventTypeID: &model.OptionalString{Value: "curling"},
+		SportData:   &model.SportEvent{},
 	}
 
 	out, err := client.TransformEvent(context.Background(), partial, full)
@@ -22,7 +26,25 @@ func TestTransformEvent_SkipsWhenEventTypeNotUpdated(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if out != nil {
-		t.Fatalf("expected nil output when EventTypeID not updated, got %#v", out)
+		t.Fatalf("expected nil output for an event type without a sport, got %#v", out)
+	}
+}
+
+func TestTransformEvent_AppliesWhenEventTypeNotUpdated(t *testing.T) {
+	client := sporttransform.NewSportTransformClient()
+
+	partial := &model.Event{ID: "evt-1", Name: &model.OptionalString{Value: "Renamed"}}
+	full := &model.Event{
+		ID:          "evt-1",
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetSportData().GetName().GetValue() != "Soccer" {
+		t.Fatalf("expected the sport of the stored event type to be set, got %#v", out)
 	}
 }
 
@@ -101,9 +123,88 @@ func TestTransformEvent_SetsSportName(t *testing.T) {
 	}
 }
 
+func TestTransformEvent_KeepsFeedSuppliedName(t *testing.T) {
+	client := sporttransform.NewSportTransformClientWithRegistry(sporttypes.NewRegistry(sporttypes.SportType{
+		EventTypeID: "soccer",
+		Name:        "Soccer",
+		Region:      "Europe",
+	}))
+
+	partial := &model.Event{ID: "evt-6", EventTypeID: &model.OptionalString{Value: "soccer"}}
+	full := &model.Event{
+		ID:          "evt-6",
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+		SportData:   &model.SportEvent{Name: &model.OptionalString{Value: "Football"}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || out.SportData == nil {
+		t.Fatalf("expected sport data to be set, got %#v", out)
+	}
+	if out.SportData.Name != nil {
+		t.Fatalf("expected the feed supplied name to be kept, got %v", out.SportData.Name)
+	}
+	if out.SportData.GetRegion().GetValue() != "Europe" {
+		t.Fatalf("expected default region %q, got %q", "Europe", out.SportData.GetRegion().GetValue())
+	}
+}
+
+func TestTransformEvent_ReappliesChangedMapping(t *testing.T) {
+	registry := sporttypes.NewRegistry(sporttypes.SportType{
+		EventTypeID: "soccer",
+		Name:        "Football",
+		Region:      "Europe",
+		League:      "Premier League",
+	})
+	client := sporttransform.NewSportTransformClientWithConfig(sporttransform.Config{
+		Registry:     registry,
+		ReapplyNames: true,
+	})
+
+	partial := &model.Event{ID: "evt-5", Name: &model.OptionalString{Value: "Renamed"}}
+	full := &model.Event{
+		ID:          "evt-5",
+		EventTypeID: &model.OptionalString{Value: "soccer"},
+		SportData: &model.SportEvent{
+			Name:   &model.OptionalString{Value: "Soccer"},
+			League: &model.OptionalString{Value: "Championship"},
+		},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || out.SportData == nil {
+		t.Fatalf("expected sport data to be set, got %#v", out)
+	}
+	if out.SportData.GetName().GetValue() != "Football" {
+		t.Fatalf("expected sport name %q, got %q", "Football", out.SportData.GetName().GetValue())
+	}
+	if out.SportData.GetRegion().GetValue() != "Europe" {
+		t.Fatalf("expected default region %q, got %q", "Europe", out.SportData.GetRegion().GetValue())
+	}
+	if out.SportData.League != nil {
+		t.Fatalf("expected league to be kept, got %v", out.SportData.League)
+	}
+}
+
 func TestGetName(t *testing.T) {
 	client := sporttransform.NewSportTransformClient()
 	if got := client.GetName(); got != "SportsTransform" {
 		t.Fatalf("expected name %q, got %q", "SportsTransform", got)
 	}
 }
+
+func TestEventTypeIDs(t *testing.T) {
+	client, ok := sporttransform.NewSportTransformClient().(transforms.Scoped)
+	if !ok {
+		t.Fatalf("expected the sport transform to declare the event types it applies to")
+	}
+	if got, want := client.EventTypeIDs(), []string{"rugby_league", "soccer"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected event types %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,160 @@
+// Package statusrolluptransform supplies a statusRollupTransformClient
+package statusrolluptransform
+
+import (
+	"context"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// Config holds which hierarchy rules are enforced between an event, its markets and their selections.
+// Reopening is never cascaded, as markets and selections can be suspended for their own reasons.
+type Config struct {
+	CascadeClose              bool // closing an event or market closes everything below it
+	CascadeSuspend            bool // suspending an event or market suspends everything open below it
+	CloseWhenAllMarketsClosed bool // an event closes once every one of its markets is closed
+}
+
+// DefaultConfig returns a Config enforcing every hierarchy rule
+func DefaultConfig() Config {
+	return Config{CascadeClose: true, CascadeSuspend: true, CloseWhenAllMarketsClosed: true}
+}
+
+type statusRollupTransformClient struct {
+	config Config
+}
+
+// NewStatusRollupTransformClient creates a new StatusRollup transform client
+func NewStatusRollupTransformClient(config Config) transforms.TransformClient {
+	return &statusRollupTransformClient{config: config}
+}
+
+// delta collects the status changes made to the markets and selections of an event
+type delta struct {
+	event   *model.OptionalBettingStatus
+	markets map[string]*model.Market
+	order   []string
+}
+
+func (d *delta) market(id string) *model.Market {
+	if m, ok := d.markets[id]; ok {
+		return m
+	}
+	m := &model.Market{ID: id}
+	d.markets[id] = m
+	d.order = append(d.order, id)
+	return m
+}
+
+func (d *delta) setMarket(id string, status model.BettingStatus) {
+	d.market(id).BettingStatus = &model.OptionalBettingStatus{Value: status}
+}
+
+func (d *delta) setSelection(marketID, id string, status model.BettingStatus) {
+	m := d.market(marketID)
+	m.Selections = append(m.Selections, &model.Selection{
+		ID:            id,
+		BettingStatus: &model.OptionalBettingStatus{Value: status},
+	})
+}
+
+// TransformEvent cascades the status changes of the partial update down the hierarchy and rolls closed markets up to
+// the event. Run it in a stage WithOutputs to also cascade the statuses set by the transforms before it.
+func (t *statusRollupTransformClient) TransformEvent(_ context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	var outDelta *model.Event
+	d := &delta{markets: map[string]*model.Market{}}
+
+	cascaded := map[string]bool{}
+	if partialUpdate.GetBettingStatus() != nil {
+		status := fullModel.GetBettingStatus().GetValue()
+		if t.cascades(status) {
+			for _, market := range fullModel.GetMarkets() {
+				t.cascadeMarket(d, market, status, true)
+				cascaded[market.GetID()] = true
+			}
+		}
+	}
+
+	for _, partialMarket := range partialUpdate.GetMarkets() {
+		if partialMarket.GetBettingStatus() == nil || cascaded[partialMarket.GetID()] {
+			continue
+		}
+		for _, market := range fullModel.GetMarkets() {
+			if market.GetID() == partialMarket.GetID() && t.cascades(market.GetBettingStatus().GetValue()) {
+				t.cascadeMarket(d, market, market.GetBettingStatus().GetValue(), false)
+			}
+		}
+	}
+
+	if t.config.CloseWhenAllMarketsClosed && len(partialUpdate.GetMarkets()) > 0 &&
+		fullModel.GetBettingStatus().GetValue() != model.BettingStatus_BettingClosed && allClosed(fullModel, d) {
+		d.event = &model.OptionalBettingStatus{Value: model.BettingStatus_BettingClosed}
+	}
+
+	if d.event == nil && len(d.order) == 0 {
+		return outDelta, nil
+	}
+
+	outDelta = &model.Event{
+		ID:            partialUpdate.ID,
+		BettingStatus: d.event,
+	}
+	for _, id := range d.order {
+		outDelta.Markets = append(outDelta.Markets, d.markets[id])
+	}
+
+	return outDelta, nil
+}
+
+// cascades reports whether a status is pushed down the hierarchy under the current config
+func (t *statusRollupTransformClient) cascades(status model.BettingStatus) bool {
+	return (status == model.BettingStatus_BettingClosed && t.config.CascadeClose) ||
+		(status == model.BettingStatus_BettingSuspended && t.config.CascadeSuspend)
+}
+
+// cascadeMarket pushes status down to the market (when it comes from the event) and to its selections
+func (t *statusRollupTransformClient) cascadeMarket(d *delta, market *model.Market, status model.BettingStatus,
+	includeMarket bool,
+) {
+	if includeMarket && overrides(status, market.GetBettingStatus().GetValue()) {
+		d.setMarket(market.GetID(), status)
+	}
+	for _, selection := range market.GetSelections() {
+		if overrides(status, selection.GetBettingStatus().GetValue()) {
+			d.setSelection(market.GetID(), selection.GetID(), status)
+		}
+	}
+}
+
+// overrides reports whether a cascaded status replaces the current one. Closing replaces anything that is not already
+// closed, suspending only replaces open (or unknown) statuses so closed markets and selections stay closed.
+func overrides(cascaded, current model.BettingStatus) bool {
+	if cascaded == model.BettingStatus_BettingClosed {
+		return current != model.BettingStatus_BettingClosed
+	}
+	return current == model.BettingStatus_BettingOpen || current == model.BettingStatus_BettingUnknown
+}
+
+// allClosed reports whether every market of the event is closed once the delta is applied
+func allClosed(event *model.Event, d *delta) bool {
+	if len(event.GetMarkets()) == 0 {
+		return false
+	}
+	for _, market := range event.GetMarkets() {
+		status := market.GetBettingStatus().GetValue()
+		if m, ok := d.markets[market.GetID()]; ok && m.BettingStatus != nil {
+			status = m.BettingStatus.GetValue()
+		}
+		if status != model.BettingStatus_BettingClosed {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *statusRollupTransformClient) GetName() string {
+	return "StatusRollupTransform"
+}
@@ -0,0 +1,165 @@
+package statusrolluptransform_test
+
+import (
+	"context"
+	"testing"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/core/transforms/statusrolluptransform"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+func status(s model.BettingStatus) *model.OptionalBettingStatus {
+	return &model.OptionalBettingStatus{Value: s}
+}
+
+func fullEvent(eventStatus model.BettingStatus) *model.Event {
+	return &model.Event{
+		ID:            "evt-1",
+		BettingStatus: status(eventStatus),
+		Markets: []*model.Market{
+			{
+				ID:            "m1",
+				BettingStatus: status(model.BettingStatus_BettingOpen),
+				Selections: []*model.Selection{
+					{ID: "s1", BettingStatus: status(model.BettingStatus_BettingOpen)},
+					{ID: "s2", BettingStatus: status(model.BettingStatus_BettingClosed)},
+				},
+			},
+			{ID: "m2", BettingStatus: status(model.BettingStatus_BettingClosed)},
+		},
+	}
+}
+
+func TestTransformEvent_SkipsWhenStatusNotUpdated(t *testing.T) {
+	client := statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.DefaultConfig())
+
+	partial := &model.Event{ID: "evt-1", Name: &model.OptionalString{Value: "Renamed"}}
+
+	out, err := client.TransformEvent(context.Background(), partial, fullEvent(model.BettingStatus_BettingClosed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("expected nil output when no status updated, got %#v", out)
+	}
+}
+
+func TestTransformEvent_CascadesEventSuspension(t *testing.T) {
+	client := statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.DefaultConfig())
+
+	partial := &model.Event{ID: "evt-1", BettingStatus: status(model.BettingStatus_BettingSuspended)}
+
+	out, err := client.TransformEvent(context.Background(), partial, fullEvent(model.BettingStatus_BettingSuspended))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out.Markets) != 1 {
+		t.Fatalf("expected a delta for market m1 only, got %#v", out)
+	}
+
+	market := out.Markets[0]
+	if market.GetID() != "m1" || market.GetBettingStatus().GetValue() != model.BettingStatus_BettingSuspended {
+		t.Fatalf("expected market m1 to be suspended, got %+v", market)
+	}
+	if len(market.Selections) != 1 || market.Selections[0].GetID() != "s1" {
+		t.Fatalf("expected only the open selection to be suspended, got %+v", market.Selections)
+	}
+}
+
+func TestTransformEvent_CascadesMarketClose(t *testing.T) {
+	config := statusrolluptransform.DefaultConfig()
+	config.CloseWhenAllMarketsClosed = false
+	client := statusrolluptransform.NewStatusRollupTransformClient(config)
+
+	full := fullEvent(model.BettingStatus_BettingOpen)
+	full.Markets[0].BettingStatus = status(model.BettingStatus_BettingClosed)
+	partial := &model.Event{
+		ID:      "evt-1",
+		Markets: []*model.Market{{ID: "m1", BettingStatus: status(model.BettingStatus_BettingClosed)}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || out.BettingStatus != nil || len(out.Markets) != 1 {
+		t.Fatalf("expected a delta for market m1 only, got %#v", out)
+	}
+	selections := out.Markets[0].Selections
+	if len(selections) != 1 || selections[0].GetBettingStatus().GetValue() != model.BettingStatus_BettingClosed {
+		t.Fatalf("expected the open selection to be closed, got %+v", selections)
+	}
+}
+
+func TestTransformEvent_ClosesEventWhenAllMarketsClosed(t *testing.T) {
+	client := statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.DefaultConfig())
+
+	full := fullEvent(model.BettingStatus_BettingOpen)
+	full.Markets[0].BettingStatus = status(model.BettingStatus_BettingClosed)
+	partial := &model.Event{
+		ID:      "evt-1",
+		Markets: []*model.Market{{ID: "m1", BettingStatus: status(model.BettingStatus_BettingClosed)}},
+	}
+
+	out, err := client.TransformEvent(context.Background(), partial, full)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.GetBettingStatus().GetValue() != model.BettingStatus_BettingClosed {
+		t.Fatalf("expected event to be closed, got %#v", out)
+	}
+}
+
+// suspendTransform suspends market m1, as the overround or a trading rule would
+type suspendTransform struct{}
+
+func (suspendTransform) TransformEvent(_ context.Context, _, fullModel *model.Event) (*model.Event, error) {
+	return &model.Event{
+		ID:      fullModel.GetID(),
+		Markets: []*model.Market{{ID: "m1", BettingStatus: status(model.BettingStatus_BettingSuspended)}},
+	}, nil
+}
+
+func (suspendTransform) GetName() string {
+	return "SuspendTransform"
+}
+
+func TestTransformEvent_CascadesTransformSuspension(t *testing.T) {
+	pipeline, err := transforms.NewPipeline(
+		transforms.Stage{Transform: suspendTransform{}},
+		transforms.Stage{
+			Transform:   statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.DefaultConfig()),
+			DependsOn:   []string{"SuspendTransform"},
+			WithOutputs: true,
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	partial := &model.Event{ID: "evt-1", Name: &model.OptionalString{Value: "Renamed"}}
+	out, failures, err := pipeline.Run(context.Background(), merger.NewInlineMergerClient(), partial,
+		fullEvent(model.BettingStatus_BettingOpen))
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("unexpected failures %v, error %v", failures, err)
+	}
+
+	market := out.GetMarkets()[0]
+	if market.GetBettingStatus().GetValue() != model.BettingStatus_BettingSuspended {
+		t.Fatalf("expected market m1 to be suspended, got %+v", market)
+	}
+	for _, selection := range market.GetSelections() {
+		if selection.GetBettingStatus().GetValue() == model.BettingStatus_BettingOpen {
+			t.Fatalf("expected the selections of the suspended market to be suspended, got %+v", selection)
+		}
+	}
+}
+
+func TestGetName(t *testing.T) {
+	client := statusrolluptransform.NewStatusRollupTransformClient(statusrolluptransform.DefaultConfig())
+	if got := client.GetName(); got != "StatusRollupTransform" {
+		t.Fatalf("expected name %q, got %q", "StatusRollupTransform", got)
+	}
+}
@@ -12,3 +12,44 @@ type TransformClient interface {
 	TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (*model.Event, error)
 	GetName() string
 }
+
+// Scoped is implemented by transforms that only apply to some event types, the pipeline only runs them for events
+// with one of the returned EventTypeIDs
+type Scoped interface {
+	EventTypeIDs() []string
+}
+
+// Predicate decides whether a transform applies to an update
+type Predicate func(partialUpdate, fullModel *model.Event) bool
+
+// TransformFunc runs a transform on an update, like TransformClient.TransformEvent
+type TransformFunc func(ctx context.Context, partialUpdate, fullModel *model.Event) (*model.Event, error)
+
+// wrappedTransform is a transform whose runs go through a TransformFunc, keeping its name and event types
+type wrappedTransform struct {
+	transform      TransformClient
+	transformEvent TransformFunc
+}
+
+// Wrap returns the transform running through transformEvent, which is expected to call the transform, e.g to
+// instrument it. The event types of a Scoped transform are passed through so the pipeline still routes it.
+func Wrap(transform TransformClient, transformEvent TransformFunc) TransformClient {
+	return &wrappedTransform{transform: transform, transformEvent: transformEvent}
+}
+
+func (t *wrappedTransform) TransformEvent(ctx context.Context, partialUpdate, fullModel *model.Event) (
+	*model.Event, error,
+) {
+	return t.transformEvent(ctx, partialUpdate, fullModel)
+}
+
+func (t *wrappedTransform) GetName() string {
+	return t.transform.GetName()
+}
+
+func (t *wrappedTransform) EventTypeIDs() []string {
+	if scoped, ok := t.transform.(Scoped); ok {
+		return scoped.EventTypeIDs()
+	}
+	return nil
+}
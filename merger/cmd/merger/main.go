@@ -0,0 +1,100 @@
+// Package main is the main entry point for the merger service
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+
+	recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/reflection"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+)
+
+const (
+	// AppName - to allow overrides at build time
+	AppName = "merger"
+
+	// Version - Should be set by CI pipeline
+	Version = 1
+)
+
+// Application entry point for the Merger Service Host.
+func main() {
+	app := cli.NewApp()
+	app.Name = AppName
+	app.Version = fmt.Sprintf("%v", Version)
+	app.Usage = "Merger"
+	app.Description = "Merges partial updates of the Core system model over gRPC"
+	app.Flags = []cli.Flag{
+		cli.IntFlag{
+			Name:   "grpc-port",
+			EnvVar: "MERGER_GRPC_PORT",
+			Value:  50052,
+			Usage:  "port the Merger gRPC service listens on",
+		},
+	}
+	app.Action = func(c *cli.Context) error {
+		log.SetFormatter(&log.TextFormatter{})
+
+		recoveryOpts := []recovery.Option{
+			recovery.WithRecoveryHandler(func(p interface{}) error {
+				log.WithField("panic", p).
+					WithField("Stack", string(debug.Stack())).Error("request_panic_caught")
+				return fmt.Errorf("server_panic")
+			}),
+		}
+		grpcServer := grpc.NewServer(
+			grpc.MaxRecvMsgSize(1024*1024*64),
+			grpc.MaxSendMsgSize(1024*1024*64),
+			grpc.ChainUnaryInterceptor(recovery.UnaryServerInterceptor(recoveryOpts...)),
+		)
+		merger.RegisterMergerServer(grpcServer, merger.NewServer(merger.NewInlineMergerClient()))
+		reflection.Register(grpcServer)
+
+		address := fmt.Sprintf(":%d", c.Int("grpc-port"))
+		lis, err := net.Listen("tcp", address)
+		if err != nil {
+			return fmt.Errorf("service_start_failed: %v", err)
+		}
+
+		errChan := make(chan error, 1)
+		go func() {
+			log.WithField("address", address).Info("service_start")
+			if err := grpcServer.Serve(lis); err != nil {
+				errChan <- err
+			}
+		}()
+
+		// Wait for the signal to die
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals,
+			syscall.SIGHUP,
+			syscall.SIGINT,
+			syscall.SIGTERM,
+			syscall.SIGQUIT)
+
+		select {
+		case err := <-errChan:
+			log.WithError(err).Error("service_error")
+		case sig := <-signals:
+			log.WithField("signal", sig).Warn("shutdown_signal")
+		}
+
+		grpcServer.GracefulStop()
+		log.Info("shutdown_complete")
+		return nil
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		os.Exit(-1)
+	}
+}
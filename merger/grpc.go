@@ -0,0 +1,77 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// DefaultTimeout is the deadline of each call to a Merger service without a Timeout
+const DefaultTimeout = 500 * time.Millisecond
+
+// Config configures the client of a Merger service
+type Config struct {
+	Address     string
+	Timeout     time.Duration                    // deadline of each call, defaults to DefaultTimeout
+	Credentials credentials.TransportCredentials // e.g TLS, defaults to plaintext
+}
+
+type grpcMergerClient struct {
+	client  MergerClient
+	timeout time.Duration
+}
+
+// NewGRPCMergerClient creates a new instance of grpcMergerClient, merging events with a Merger service.
+func NewGRPCMergerClient(conn grpc.ClientConnInterface, config Config) ServiceClient {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &grpcMergerClient{client: NewMergerClient(conn), timeout: config.Timeout}
+}
+
+// Dial connects to a Merger service, the connection has to be closed once the client is no longer used.
+func Dial(config Config) (ServiceClient, *grpc.ClientConn, error) {
+	creds := config.Credentials
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(config.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to merger %v: %w", config.Address, err)
+	}
+	return NewGRPCMergerClient(conn, config), conn, nil
+}
+
+func (c *grpcMergerClient) MergeEvent(ctx context.Context, left, right *model.Event) (*model.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.MergeEvent(ctx, &MergeEventRequest{Left: left, Right: right})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetEvent(), nil
+}
+
+type server struct {
+	client ServiceClient
+}
+
+// NewServer exposes a ServiceClient as a Merger service, register it with RegisterMergerServer.
+func NewServer(client ServiceClient) MergerServer {
+	return &server{client: client}
+}
+
+func (s *server) MergeEvent(ctx context.Context, req *MergeEventRequest) (*MergeEventResponse, error) {
+	event, err := s.client.MergeEvent(ctx, req.GetLeft(), req.GetRight())
+	if err != nil {
+		return nil, err
+	}
+	return &MergeEventResponse{Event: event}, nil
+}
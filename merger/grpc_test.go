@@ -0,0 +1,90 @@
+package merger_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/technology/pricekinetics/tools/codetest/merger"
+	"git.neds.sh/technology/pricekinetics/tools/codetest/model"
+)
+
+// dial serves a Merger service over an in-memory connection and connects to it
+func dial(t *testing.T, server merger.MergerServer) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	merger.RegisterMergerServer(srv, server)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestGRPCMergerClient_MergeEvent(t *testing.T) {
+	conn := dial(t, merger.NewServer(merger.NewInlineMergerClient()))
+
+	left := &model.Event{
+		ID:   "evt-1",
+		Name: &model.OptionalString{Value: "Left"},
+		Markets: []*model.Market{
+			{ID: "mkt-1", Name: &model.OptionalString{Value: "Head to Head"}},
+		},
+	}
+	right := &model.Event{
+		ID:        "evt-1",
+		StartTime: &model.OptionalInt64{Value: 1700000000},
+		Markets: []*model.Market{
+			{ID: "mkt-1", Name: &model.OptionalString{Value: "Match Result"}},
+			{ID: "mkt-2", Name: &model.OptionalString{Value: "Line"}},
+		},
+	}
+
+	got, err := merger.NewGRPCMergerClient(conn, merger.Config{}).MergeEvent(context.Background(), left, right)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := merger.NewInlineMergerClient().MergeEvent(context.Background(), left, right)
+	if !proto.Equal(got, want) {
+		t.Fatalf("expected the remote merge to match the inline merge\nwant %v\ngot  %v", want, got)
+	}
+}
+
+// slowServer answers once the call is cancelled
+type slowServer struct{}
+
+func (slowServer) MergeEvent(ctx context.Context, _ *merger.MergeEventRequest) (*merger.MergeEventResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGRPCMergerClient_Timeout(t *testing.T) {
+	client := merger.NewGRPCMergerClient(dial(t, slowServer{}), merger.Config{Timeout: 20 * time.Millisecond})
+
+	_, err := client.MergeEvent(context.Background(), &model.Event{ID: "evt-1"}, &model.Event{ID: "evt-1"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected the call to time out, got %v", err)
+	}
+}
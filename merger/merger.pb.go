@@ -0,0 +1,242 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.33.4
+// source: merger.proto
+
+//go:build !protoopaque
+
+package merger
+
+import (
+	model "git.neds.sh/technology/pricekinetics/tools/codetest/model"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MergeEventRequest struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	Left          *model.Event           `protobuf:"bytes,1,opt,name=Left,proto3" json:"Left,omitempty"`   // the event merged into
+	Right         *model.Event           `protobuf:"bytes,2,opt,name=Right,proto3" json:"Right,omitempty"` // the changes, its values overwrite the ones of Left
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeEventRequest) Reset() {
+	*x = MergeEventRequest{}
+	mi := &file_merger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeEventRequest) ProtoMessage() {}
+
+func (x *MergeEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *MergeEventRequest) GetLeft() *model.Event {
+	if x != nil {
+		return x.Left
+	}
+	return nil
+}
+
+func (x *MergeEventRequest) GetRight() *model.Event {
+	if x != nil {
+		return x.Right
+	}
+	return nil
+}
+
+func (x *MergeEventRequest) SetLeft(v *model.Event) {
+	x.Left = v
+}
+
+func (x *MergeEventRequest) SetRight(v *model.Event) {
+	x.Right = v
+}
+
+func (x *MergeEventRequest) HasLeft() bool {
+	if x == nil {
+		return false
+	}
+	return x.Left != nil
+}
+
+func (x *MergeEventRequest) HasRight() bool {
+	if x == nil {
+		return false
+	}
+	return x.Right != nil
+}
+
+func (x *MergeEventRequest) ClearLeft() {
+	x.Left = nil
+}
+
+func (x *MergeEventRequest) ClearRight() {
+	x.Right = nil
+}
+
+type MergeEventRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Left  *model.Event
+	Right *model.Event
+}
+
+func (b0 MergeEventRequest_builder) Build() *MergeEventRequest {
+	m0 := &MergeEventRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.Left = b.Left
+	x.Right = b.Right
+	return m0
+}
+
+type MergeEventResponse struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	Event         *model.Event           `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeEventResponse) Reset() {
+	*x = MergeEventResponse{}
+	mi := &file_merger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeEventResponse) ProtoMessage() {}
+
+func (x *MergeEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *MergeEventResponse) GetEvent() *model.Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *MergeEventResponse) SetEvent(v *model.Event) {
+	x.Event = v
+}
+
+func (x *MergeEventResponse) HasEvent() bool {
+	if x == nil {
+		return false
+	}
+	return x.Event != nil
+}
+
+func (x *MergeEventResponse) ClearEvent() {
+	x.Event = nil
+}
+
+type MergeEventResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Event *model.Event
+}
+
+func (b0 MergeEventResponse_builder) Build() *MergeEventResponse {
+	m0 := &MergeEventResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.Event = b.Event
+	return m0
+}
+
+var File_merger_proto protoreflect.FileDescriptor
+
+const file_merger_proto_rawDesc = "" +
+	"\n" +
+	"\fmerger.proto\x12\tmerger.v1\x1a\x11model/event.proto\"Y\n" +
+	"\x11MergeEventRequest\x12 \n" +
+	"\x04Left\x18\x01 \x01(\v2\f.model.EventR\x04Left\x12\"\n" +
+	"\x05Right\x18\x02 \x01(\v2\f.model.EventR\x05Right\"8\n" +
+	"\x12MergeEventResponse\x12\"\n" +
+	"\x05Event\x18\x01 \x01(\v2\f.model.EventR\x05Event2U\n" +
+	"\x06Merger\x12K\n" +
+	"\n" +
+	"MergeEvent\x12\x1c.merger.v1.MergeEventRequest\x1a\x1d.merger.v1.MergeEventResponse\"\x00B<Z:git.neds.sh/technology/pricekinetics/tools/codetest/mergerb\x06proto3"
+
+var file_merger_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_merger_proto_goTypes = []any{
+	(*MergeEventRequest)(nil),  // 0: merger.v1.MergeEventRequest
+	(*MergeEventResponse)(nil), // 1: merger.v1.MergeEventResponse
+	(*model.Event)(nil),        // 2: model.Event
+}
+var file_merger_proto_depIdxs = []int32{
+	2, // 0: merger.v1.MergeEventRequest.Left:type_name -> model.Event
+	2, // 1: merger.v1.MergeEventRequest.Right:type_name -> model.Event
+	2, // 2: merger.v1.MergeEventResponse.Event:type_name -> model.Event
+	0, // 3: merger.v1.Merger.MergeEvent:input_type -> merger.v1.MergeEventRequest
+	1, // 4: merger.v1.Merger.MergeEvent:output_type -> merger.v1.MergeEventResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_merger_proto_init() }
+func file_merger_proto_init() {
+	if File_merger_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_merger_proto_rawDesc), len(file_merger_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_merger_proto_goTypes,
+		DependencyIndexes: file_merger_proto_depIdxs,
+		MessageInfos:      file_merger_proto_msgTypes,
+	}.Build()
+	File_merger_proto = out.File
+	file_merger_proto_goTypes = nil
+	file_merger_proto_depIdxs = nil
+}
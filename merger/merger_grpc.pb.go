@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v6.33.4
+// source: merger.proto
+
+package merger
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Merger_MergeEvent_FullMethodName = "/merger.v1.Merger/MergeEvent"
+)
+
+// MergerClient is the client API for Merger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MergerClient interface {
+	// MergeEvent merges two partial events together
+	MergeEvent(ctx context.Context, in *MergeEventRequest, opts ...grpc.CallOption) (*MergeEventResponse, error)
+}
+
+type mergerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMergerClient(cc grpc.ClientConnInterface) MergerClient {
+	return &mergerClient{cc}
+}
+
+func (c *mergerClient) MergeEvent(ctx context.Context, in *MergeEventRequest, opts ...grpc.CallOption) (*MergeEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MergeEventResponse)
+	err := c.cc.Invoke(ctx, Merger_MergeEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MergerServer is the server API for Merger service.
+// All implementations should embed UnimplementedMergerServer
+// for forward compatibility.
+type MergerServer interface {
+	// MergeEvent merges two partial events together
+	MergeEvent(context.Context, *MergeEventRequest) (*MergeEventResponse, error)
+}
+
+// UnimplementedMergerServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMergerServer struct{}
+
+func (UnimplementedMergerServer) MergeEvent(context.Context, *MergeEventRequest) (*MergeEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MergeEvent not implemented")
+}
+func (UnimplementedMergerServer) testEmbeddedByValue() {}
+
+// UnsafeMergerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MergerServer will
+// result in compilation errors.
+type UnsafeMergerServer interface {
+	mustEmbedUnimplementedMergerServer()
+}
+
+func RegisterMergerServer(s grpc.ServiceRegistrar, srv MergerServer) {
+	// If the following call panics, it indicates UnimplementedMergerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Merger_ServiceDesc, srv)
+}
+
+func _Merger_MergeEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MergerServer).MergeEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Merger_MergeEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MergerServer).MergeEvent(ctx, req.(*MergeEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Merger_ServiceDesc is the grpc.ServiceDesc for Merger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Merger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "merger.v1.Merger",
+	HandlerType: (*MergerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MergeEvent",
+			Handler:    _Merger_MergeEvent_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "merger.proto",
+}
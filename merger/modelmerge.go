@@ -59,6 +59,10 @@ func MergeEvent(ctx context.Context, left, right *model.Event) *model.Event {
 		result.Markets = mergedMarkets
 	}
 	result.EventTypeID = MergeOptionalString(ctx, left.EventTypeID, right.EventTypeID)
+	result.Sequence = MergeOptionalInt64(ctx, left.Sequence, right.Sequence)
+	result.StartActioned = MergeOptionalInt64(ctx, left.StartActioned, right.StartActioned)
+	result.StatusRule = MergeOptionalString(ctx, left.StatusRule, right.StatusRule)
+	result.StatusBeforeRule = MergeOptionalBettingStatus(ctx, left.StatusBeforeRule, right.StatusBeforeRule)
 	return result
 }
 
@@ -101,6 +105,9 @@ func MergeMarket(ctx context.Context, left, right *model.Market) *model.Market {
 	result.Name = MergeOptionalString(ctx, left.Name, right.Name)
 	result.StartTime = MergeOptionalInt64(ctx, left.StartTime, right.StartTime)
 	result.BettingStatus = MergeOptionalBettingStatus(ctx, left.BettingStatus, right.BettingStatus)
+	result.Overround = MergeOptionalDouble(ctx, left.Overround, right.Overround)
+	result.StatusRule = MergeOptionalString(ctx, left.StatusRule, right.StatusRule)
+	result.StatusBeforeRule = MergeOptionalBettingStatus(ctx, left.StatusBeforeRule, right.StatusBeforeRule)
 
 	// Generate the difference for Selections with a slice of Selection
 	mergedSelections := MergeSelectionSlice(ctx, left.Selections, right.Selections)
@@ -128,6 +135,35 @@ func MergeSelection(ctx context.Context, left, right *model.Selection) *model.Se
 	result.Name = MergeOptionalString(ctx, left.Name, right.Name)
 	result.BettingStatus = MergeOptionalBettingStatus(ctx, left.BettingStatus, right.BettingStatus)
 	result.Price = MergeOptionalDouble(ctx, left.Price, right.Price)
+	result.FeedPrice = MergeOptionalDouble(ctx, left.FeedPrice, right.FeedPrice)
+	result.PriceFlag = MergeOptionalString(ctx, left.PriceFlag, right.PriceFlag)
+	result.PriceHistory = MergePriceHistory(ctx, left.PriceHistory, right.PriceHistory)
+	result.StatusRule = MergeOptionalString(ctx, left.StatusRule, right.StatusRule)
+	result.StatusBeforeRule = MergeOptionalBettingStatus(ctx, left.StatusBeforeRule, right.StatusBeforeRule)
+	return result
+}
+
+// MergePriceHistory generates a new instance of the PriceHistory type, where two input values are merged. Values on
+// the left are overwritten with values from the right where they exist. Changes are replaced as a whole, as the right
+// operand always carries the full list of recent changes.
+func MergePriceHistory(ctx context.Context, left, right *model.PriceHistory) *model.PriceHistory {
+	// Handle trivial cases
+	if right == nil {
+		return left
+	}
+	if left == nil {
+		return right
+	}
+
+	// Create the new target
+	result := &model.PriceHistory{}
+
+	result.OpeningPrice = MergeOptionalDouble(ctx, left.OpeningPrice, right.OpeningPrice)
+	result.PreviousPrice = MergeOptionalDouble(ctx, left.PreviousPrice, right.PreviousPrice)
+	result.Changes = left.Changes
+	if len(right.Changes) > 0 {
+		result.Changes = right.Changes
+	}
 	return result
 }
 
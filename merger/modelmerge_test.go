@@ -110,12 +110,16 @@ func TestMergeSelection(t *testing.T) {
 		Name:          &model.OptionalString{Value: "Left"},
 		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
 		Price:         &model.OptionalDouble{Value: 1.1},
+		FeedPrice:     &model.OptionalDouble{Value: 1.013},
+		PriceFlag:     &model.OptionalString{Value: "snapped"},
 	}
 	right := &model.Selection{
 		ID:            "sel-1",
 		Name:          &model.OptionalString{Value: "Right"},
 		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingClosed},
 		Price:         &model.OptionalDouble{Value: 2.2},
+		FeedPrice:     &model.OptionalDouble{Deleted: true},
+		PriceFlag:     &model.OptionalString{Deleted: true},
 	}
 
 	if got := merger.MergeSelection(context.Background(), nil, right); got != right {
@@ -130,17 +134,50 @@ func TestMergeSelection(t *testing.T) {
 		t.Fatalf("expected ID %q, got %q", "sel-1", out.ID)
 	}
 	if out.Name.Value != "Right" || out.BettingStatus.Value != model.BettingStatus_BettingClosed ||
-		out.Price.Value != 2.2 {
+		out.Price.Value != 2.2 || !out.FeedPrice.Deleted || !out.PriceFlag.Deleted {
 		t.Fatalf("expected right values, got %+v", out)
 	}
 }
 
+func TestMergePriceHistory(t *testing.T) {
+	left := &model.PriceHistory{
+		OpeningPrice:  &model.OptionalDouble{Value: 2},
+		PreviousPrice: &model.OptionalDouble{Value: 2},
+		Changes:       []*model.PriceChange{{Price: 2, Timestamp: 1}, {Price: 2.2, Timestamp: 2}},
+	}
+	right := &model.PriceHistory{
+		PreviousPrice: &model.OptionalDouble{Value: 2.2},
+		Changes:       []*model.PriceChange{{Price: 2.2, Timestamp: 2}, {Price: 2.4, Timestamp: 3}},
+	}
+
+	if got := merger.MergePriceHistory(context.Background(), nil, right); got != right {
+		t.Fatalf("expected right when left nil")
+	}
+	if got := merger.MergePriceHistory(context.Background(), left, nil); got != left {
+		t.Fatalf("expected left when right nil")
+	}
+
+	out := merger.MergePriceHistory(context.Background(), left, right)
+	if out.OpeningPrice.Value != 2 || out.PreviousPrice.Value != 2.2 {
+		t.Fatalf("expected merged prices, got %+v", out)
+	}
+	if len(out.Changes) != 2 || out.Changes[1].Price != 2.4 {
+		t.Fatalf("expected changes from right, got %+v", out.Changes)
+	}
+
+	out = merger.MergePriceHistory(context.Background(), left, &model.PriceHistory{})
+	if len(out.Changes) != 2 || out.Changes[1].Price != 2.2 {
+		t.Fatalf("expected changes from left, got %+v", out.Changes)
+	}
+}
+
 func TestMergeMarket(t *testing.T) {
 	left := &model.Market{
 		ID:            "mkt-1",
 		Name:          &model.OptionalString{Value: "Left"},
 		StartTime:     &model.OptionalInt64{Value: 1},
 		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingOpen},
+		Overround:     &model.OptionalDouble{Value: 1.05},
 		Selections: []*model.Selection{
 			{ID: "s1", Name: &model.OptionalString{Value: "LeftS1"}},
 		},
@@ -150,6 +187,7 @@ func TestMergeMarket(t *testing.T) {
 		Name:          &model.OptionalString{Value: "Right"},
 		StartTime:     &model.OptionalInt64{Value: 2},
 		BettingStatus: &model.OptionalBettingStatus{Value: model.BettingStatus_BettingClosed},
+		Overround:     &model.OptionalDouble{Value: 1.1},
 		Selections: []*model.Selection{
 			{ID: "s1", Name: &model.OptionalString{Value: "RightS1"}},
 			{ID: "s2", Name: &model.OptionalString{Value: "RightS2"}},
@@ -168,7 +206,7 @@ func TestMergeMarket(t *testing.T) {
 		t.Fatalf("expected ID %q, got %q", "mkt-1", out.ID)
 	}
 	if out.Name.Value != "Right" || out.StartTime.Value != 2 ||
-		out.BettingStatus.Value != model.BettingStatus_BettingClosed {
+		out.BettingStatus.Value != model.BettingStatus_BettingClosed || out.Overround.Value != 1.1 {
 		t.Fatalf("expected right values, got %+v", out)
 	}
 	if len(out.Selections) != 2 {
@@ -188,6 +226,7 @@ func TestMergeEvent(t *testing.T) {
 		Name:        &model.OptionalString{Value: "Left"},
 		EventTypeID: &model.OptionalString{Value: "soccer"},
 		StartTime:   &model.OptionalInt64{Value: 1},
+		Sequence:    &model.OptionalInt64{Value: 10},
 		BettingStatus: &model.OptionalBettingStatus{
 			Value: model.BettingStatus_BettingOpen,
 		},
@@ -204,6 +243,7 @@ func TestMergeEvent(t *testing.T) {
 		Name:        &model.OptionalString{Value: "Right"},
 		EventTypeID: &model.OptionalString{Value: "soccer"},
 		StartTime:   &model.OptionalInt64{Value: 2},
+		Sequence:    &model.OptionalInt64{Value: 20},
 		BettingStatus: &model.OptionalBettingStatus{
 			Value: model.BettingStatus_BettingClosed,
 		},
@@ -229,7 +269,7 @@ func TestMergeEvent(t *testing.T) {
 		t.Fatalf("expected ID %q, got %q", "evt-1", out.ID)
 	}
 	if out.Name.Value != "Right" || out.StartTime.Value != 2 ||
-		out.BettingStatus.Value != model.BettingStatus_BettingClosed {
+		out.BettingStatus.Value != model.BettingStatus_BettingClosed || out.Sequence.Value != 20 {
 		t.Fatalf("expected right values, got %+v", out)
 	}
 	if out.SportData.Name.Value != "RightSport" || out.SportData.League.Value != "RightLeague" {
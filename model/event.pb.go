@@ -142,16 +142,20 @@ func (b0 OptionalBettingStatus_builder) Build() *OptionalBettingStatus {
 
 // Event models a betting match/race
 type Event struct {
-	state         protoimpl.MessageState `protogen:"hybrid.v1"`
-	ID            string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name          *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
-	StartTime     *OptionalInt64         `protobuf:"bytes,3,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
-	BettingStatus *OptionalBettingStatus `protobuf:"bytes,4,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
-	Markets       []*Market              `protobuf:"bytes,5,rep,name=Markets,proto3" json:"Markets,omitempty"`
-	EventTypeID   *OptionalString        `protobuf:"bytes,6,opt,name=EventTypeID,proto3" json:"EventTypeID,omitempty"`
-	SportData     *SportEvent            `protobuf:"bytes,7,opt,name=SportData,proto3" json:"SportData,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"hybrid.v1"`
+	ID               string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name             *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	StartTime        *OptionalInt64         `protobuf:"bytes,3,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	BettingStatus    *OptionalBettingStatus `protobuf:"bytes,4,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
+	Markets          []*Market              `protobuf:"bytes,5,rep,name=Markets,proto3" json:"Markets,omitempty"`
+	EventTypeID      *OptionalString        `protobuf:"bytes,6,opt,name=EventTypeID,proto3" json:"EventTypeID,omitempty"`
+	SportData        *SportEvent            `protobuf:"bytes,7,opt,name=SportData,proto3" json:"SportData,omitempty"`
+	Sequence         *OptionalInt64         `protobuf:"bytes,8,opt,name=Sequence,proto3" json:"Sequence,omitempty"`                  // source timestamp or sequence number of the last applied update
+	StartActioned    *OptionalInt64         `protobuf:"bytes,9,opt,name=StartActioned,proto3" json:"StartActioned,omitempty"`        // the StartTime at which betting was last suspended or closed by the scheduler
+	StatusRule       *OptionalString        `protobuf:"bytes,10,opt,name=StatusRule,proto3" json:"StatusRule,omitempty"`             // trading rule that set the BettingStatus
+	StatusBeforeRule *OptionalBettingStatus `protobuf:"bytes,11,opt,name=StatusBeforeRule,proto3" json:"StatusBeforeRule,omitempty"` // BettingStatus restored once the StatusRule no longer holds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Event) Reset() {
@@ -228,6 +232,34 @@ func (x *Event) GetSportData() *SportEvent {
 	return nil
 }
 
+func (x *Event) GetSequence() *OptionalInt64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return nil
+}
+
+func (x *Event) GetStartActioned() *OptionalInt64 {
+	if x != nil {
+		return x.StartActioned
+	}
+	return nil
+}
+
+func (x *Event) GetStatusRule() *OptionalString {
+	if x != nil {
+		return x.StatusRule
+	}
+	return nil
+}
+
+func (x *Event) GetStatusBeforeRule() *OptionalBettingStatus {
+	if x != nil {
+		return x.StatusBeforeRule
+	}
+	return nil
+}
+
 func (x *Event) SetID(v string) {
 	x.ID = v
 }
@@ -256,6 +288,22 @@ func (x *Event) SetSportData(v *SportEvent) {
 	x.SportData = v
 }
 
+func (x *Event) SetSequence(v *OptionalInt64) {
+	x.Sequence = v
+}
+
+func (x *Event) SetStartActioned(v *OptionalInt64) {
+	x.StartActioned = v
+}
+
+func (x *Event) SetStatusRule(v *OptionalString) {
+	x.StatusRule = v
+}
+
+func (x *Event) SetStatusBeforeRule(v *OptionalBettingStatus) {
+	x.StatusBeforeRule = v
+}
+
 func (x *Event) HasName() bool {
 	if x == nil {
 		return false
@@ -291,6 +339,34 @@ func (x *Event) HasSportData() bool {
 	return x.SportData != nil
 }
 
+func (x *Event) HasSequence() bool {
+	if x == nil {
+		return false
+	}
+	return x.Sequence != nil
+}
+
+func (x *Event) HasStartActioned() bool {
+	if x == nil {
+		return false
+	}
+	return x.StartActioned != nil
+}
+
+func (x *Event) HasStatusRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusRule != nil
+}
+
+func (x *Event) HasStatusBeforeRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusBeforeRule != nil
+}
+
 func (x *Event) ClearName() {
 	x.Name = nil
 }
@@ -311,16 +387,36 @@ func (x *Event) ClearSportData() {
 	x.SportData = nil
 }
 
+func (x *Event) ClearSequence() {
+	x.Sequence = nil
+}
+
+func (x *Event) ClearStartActioned() {
+	x.StartActioned = nil
+}
+
+func (x *Event) ClearStatusRule() {
+	x.StatusRule = nil
+}
+
+func (x *Event) ClearStatusBeforeRule() {
+	x.StatusBeforeRule = nil
+}
+
 type Event_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	ID            string
-	Name          *OptionalString
-	StartTime     *OptionalInt64
-	BettingStatus *OptionalBettingStatus
-	Markets       []*Market
-	EventTypeID   *OptionalString
-	SportData     *SportEvent
+	ID               string
+	Name             *OptionalString
+	StartTime        *OptionalInt64
+	BettingStatus    *OptionalBettingStatus
+	Markets          []*Market
+	EventTypeID      *OptionalString
+	SportData        *SportEvent
+	Sequence         *OptionalInt64
+	StartActioned    *OptionalInt64
+	StatusRule       *OptionalString
+	StatusBeforeRule *OptionalBettingStatus
 }
 
 func (b0 Event_builder) Build() *Event {
@@ -334,6 +430,10 @@ func (b0 Event_builder) Build() *Event {
 	x.Markets = b.Markets
 	x.EventTypeID = b.EventTypeID
 	x.SportData = b.SportData
+	x.Sequence = b.Sequence
+	x.StartActioned = b.StartActioned
+	x.StatusRule = b.StatusRule
+	x.StatusBeforeRule = b.StatusBeforeRule
 	return m0
 }
 
@@ -483,14 +583,17 @@ func (b0 SportEvent_builder) Build() *SportEvent {
 
 // Market models a market of betting options e.g Head to Head or Totals
 type Market struct {
-	state         protoimpl.MessageState `protogen:"hybrid.v1"`
-	ID            string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name          *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
-	StartTime     *OptionalInt64         `protobuf:"bytes,3,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
-	BettingStatus *OptionalBettingStatus `protobuf:"bytes,4,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
-	Selections    []*Selection           `protobuf:"bytes,5,rep,name=Selections,proto3" json:"Selections,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"hybrid.v1"`
+	ID               string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name             *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	StartTime        *OptionalInt64         `protobuf:"bytes,3,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	BettingStatus    *OptionalBettingStatus `protobuf:"bytes,4,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
+	Selections       []*Selection           `protobuf:"bytes,5,rep,name=Selections,proto3" json:"Selections,omitempty"`
+	Overround        *OptionalDouble        `protobuf:"bytes,6,opt,name=Overround,proto3" json:"Overround,omitempty"`               // book percentage of the open selections, 1.05 is a 105% book
+	StatusRule       *OptionalString        `protobuf:"bytes,7,opt,name=StatusRule,proto3" json:"StatusRule,omitempty"`             // trading rule that set the BettingStatus
+	StatusBeforeRule *OptionalBettingStatus `protobuf:"bytes,8,opt,name=StatusBeforeRule,proto3" json:"StatusBeforeRule,omitempty"` // BettingStatus restored once the StatusRule no longer holds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Market) Reset() {
@@ -553,6 +656,27 @@ func (x *Market) GetSelections() []*Selection {
 	return nil
 }
 
+func (x *Market) GetOverround() *OptionalDouble {
+	if x != nil {
+		return x.Overround
+	}
+	return nil
+}
+
+func (x *Market) GetStatusRule() *OptionalString {
+	if x != nil {
+		return x.StatusRule
+	}
+	return nil
+}
+
+func (x *Market) GetStatusBeforeRule() *OptionalBettingStatus {
+	if x != nil {
+		return x.StatusBeforeRule
+	}
+	return nil
+}
+
 func (x *Market) SetID(v string) {
 	x.ID = v
 }
@@ -573,6 +697,18 @@ func (x *Market) SetSelections(v []*Selection) {
 	x.Selections = v
 }
 
+func (x *Market) SetOverround(v *OptionalDouble) {
+	x.Overround = v
+}
+
+func (x *Market) SetStatusRule(v *OptionalString) {
+	x.StatusRule = v
+}
+
+func (x *Market) SetStatusBeforeRule(v *OptionalBettingStatus) {
+	x.StatusBeforeRule = v
+}
+
 func (x *Market) HasName() bool {
 	if x == nil {
 		return false
@@ -594,6 +730,27 @@ func (x *Market) HasBettingStatus() bool {
 	return x.BettingStatus != nil
 }
 
+func (x *Market) HasOverround() bool {
+	if x == nil {
+		return false
+	}
+	return x.Overround != nil
+}
+
+func (x *Market) HasStatusRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusRule != nil
+}
+
+func (x *Market) HasStatusBeforeRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusBeforeRule != nil
+}
+
 func (x *Market) ClearName() {
 	x.Name = nil
 }
@@ -606,14 +763,29 @@ func (x *Market) ClearBettingStatus() {
 	x.BettingStatus = nil
 }
 
+func (x *Market) ClearOverround() {
+	x.Overround = nil
+}
+
+func (x *Market) ClearStatusRule() {
+	x.StatusRule = nil
+}
+
+func (x *Market) ClearStatusBeforeRule() {
+	x.StatusBeforeRule = nil
+}
+
 type Market_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	ID            string
-	Name          *OptionalString
-	StartTime     *OptionalInt64
-	BettingStatus *OptionalBettingStatus
-	Selections    []*Selection
+	ID               string
+	Name             *OptionalString
+	StartTime        *OptionalInt64
+	BettingStatus    *OptionalBettingStatus
+	Selections       []*Selection
+	Overround        *OptionalDouble
+	StatusRule       *OptionalString
+	StatusBeforeRule *OptionalBettingStatus
 }
 
 func (b0 Market_builder) Build() *Market {
@@ -625,18 +797,26 @@ func (b0 Market_builder) Build() *Market {
 	x.StartTime = b.StartTime
 	x.BettingStatus = b.BettingStatus
 	x.Selections = b.Selections
+	x.Overround = b.Overround
+	x.StatusRule = b.StatusRule
+	x.StatusBeforeRule = b.StatusBeforeRule
 	return m0
 }
 
 // Selection models a betting options e.g Home Team or Over
 type Selection struct {
-	state         protoimpl.MessageState `protogen:"hybrid.v1"`
-	ID            string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name          *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
-	BettingStatus *OptionalBettingStatus `protobuf:"bytes,3,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
-	Price         *OptionalDouble        `protobuf:"bytes,4,opt,name=Price,proto3" json:"Price,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"hybrid.v1"`
+	ID               string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name             *OptionalString        `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	BettingStatus    *OptionalBettingStatus `protobuf:"bytes,3,opt,name=BettingStatus,proto3" json:"BettingStatus,omitempty"`
+	Price            *OptionalDouble        `protobuf:"bytes,4,opt,name=Price,proto3" json:"Price,omitempty"`
+	FeedPrice        *OptionalDouble        `protobuf:"bytes,5,opt,name=FeedPrice,proto3" json:"FeedPrice,omitempty"` // price as received from the feed, when it was changed or rejected
+	PriceFlag        *OptionalString        `protobuf:"bytes,6,opt,name=PriceFlag,proto3" json:"PriceFlag,omitempty"` // why the feed price was changed or rejected
+	PriceHistory     *PriceHistory          `protobuf:"bytes,7,opt,name=PriceHistory,proto3" json:"PriceHistory,omitempty"`
+	StatusRule       *OptionalString        `protobuf:"bytes,8,opt,name=StatusRule,proto3" json:"StatusRule,omitempty"`             // trading rule that set the BettingStatus
+	StatusBeforeRule *OptionalBettingStatus `protobuf:"bytes,9,opt,name=StatusBeforeRule,proto3" json:"StatusBeforeRule,omitempty"` // BettingStatus restored once the StatusRule no longer holds
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Selection) Reset() {
@@ -692,6 +872,41 @@ func (x *Selection) GetPrice() *OptionalDouble {
 	return nil
 }
 
+func (x *Selection) GetFeedPrice() *OptionalDouble {
+	if x != nil {
+		return x.FeedPrice
+	}
+	return nil
+}
+
+func (x *Selection) GetPriceFlag() *OptionalString {
+	if x != nil {
+		return x.PriceFlag
+	}
+	return nil
+}
+
+func (x *Selection) GetPriceHistory() *PriceHistory {
+	if x != nil {
+		return x.PriceHistory
+	}
+	return nil
+}
+
+func (x *Selection) GetStatusRule() *OptionalString {
+	if x != nil {
+		return x.StatusRule
+	}
+	return nil
+}
+
+func (x *Selection) GetStatusBeforeRule() *OptionalBettingStatus {
+	if x != nil {
+		return x.StatusBeforeRule
+	}
+	return nil
+}
+
 func (x *Selection) SetID(v string) {
 	x.ID = v
 }
@@ -708,6 +923,26 @@ func (x *Selection) SetPrice(v *OptionalDouble) {
 	x.Price = v
 }
 
+func (x *Selection) SetFeedPrice(v *OptionalDouble) {
+	x.FeedPrice = v
+}
+
+func (x *Selection) SetPriceFlag(v *OptionalString) {
+	x.PriceFlag = v
+}
+
+func (x *Selection) SetPriceHistory(v *PriceHistory) {
+	x.PriceHistory = v
+}
+
+func (x *Selection) SetStatusRule(v *OptionalString) {
+	x.StatusRule = v
+}
+
+func (x *Selection) SetStatusBeforeRule(v *OptionalBettingStatus) {
+	x.StatusBeforeRule = v
+}
+
 func (x *Selection) HasName() bool {
 	if x == nil {
 		return false
@@ -729,6 +964,41 @@ func (x *Selection) HasPrice() bool {
 	return x.Price != nil
 }
 
+func (x *Selection) HasFeedPrice() bool {
+	if x == nil {
+		return false
+	}
+	return x.FeedPrice != nil
+}
+
+func (x *Selection) HasPriceFlag() bool {
+	if x == nil {
+		return false
+	}
+	return x.PriceFlag != nil
+}
+
+func (x *Selection) HasPriceHistory() bool {
+	if x == nil {
+		return false
+	}
+	return x.PriceHistory != nil
+}
+
+func (x *Selection) HasStatusRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusRule != nil
+}
+
+func (x *Selection) HasStatusBeforeRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.StatusBeforeRule != nil
+}
+
 func (x *Selection) ClearName() {
 	x.Name = nil
 }
@@ -741,13 +1011,38 @@ func (x *Selection) ClearPrice() {
 	x.Price = nil
 }
 
+func (x *Selection) ClearFeedPrice() {
+	x.FeedPrice = nil
+}
+
+func (x *Selection) ClearPriceFlag() {
+	x.PriceFlag = nil
+}
+
+func (x *Selection) ClearPriceHistory() {
+	x.PriceHistory = nil
+}
+
+func (x *Selection) ClearStatusRule() {
+	x.StatusRule = nil
+}
+
+func (x *Selection) ClearStatusBeforeRule() {
+	x.StatusBeforeRule = nil
+}
+
 type Selection_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	ID            string
-	Name          *OptionalString
-	BettingStatus *OptionalBettingStatus
-	Price         *OptionalDouble
+	ID               string
+	Name             *OptionalString
+	BettingStatus    *OptionalBettingStatus
+	Price            *OptionalDouble
+	FeedPrice        *OptionalDouble
+	PriceFlag        *OptionalString
+	PriceHistory     *PriceHistory
+	StatusRule       *OptionalString
+	StatusBeforeRule *OptionalBettingStatus
 }
 
 func (b0 Selection_builder) Build() *Selection {
@@ -758,6 +1053,191 @@ func (b0 Selection_builder) Build() *Selection {
 	x.Name = b.Name
 	x.BettingStatus = b.BettingStatus
 	x.Price = b.Price
+	x.FeedPrice = b.FeedPrice
+	x.PriceFlag = b.PriceFlag
+	x.PriceHistory = b.PriceHistory
+	x.StatusRule = b.StatusRule
+	x.StatusBeforeRule = b.StatusBeforeRule
+	return m0
+}
+
+// PriceHistory models how the price of a selection moved over time
+type PriceHistory struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	OpeningPrice  *OptionalDouble        `protobuf:"bytes,1,opt,name=OpeningPrice,proto3" json:"OpeningPrice,omitempty"`
+	PreviousPrice *OptionalDouble        `protobuf:"bytes,2,opt,name=PreviousPrice,proto3" json:"PreviousPrice,omitempty"`
+	Changes       []*PriceChange         `protobuf:"bytes,3,rep,name=Changes,proto3" json:"Changes,omitempty"` // the most recent changes, oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceHistory) Reset() {
+	*x = PriceHistory{}
+	mi := &file_event_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceHistory) ProtoMessage() {}
+
+func (x *PriceHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_event_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *PriceHistory) GetOpeningPrice() *OptionalDouble {
+	if x != nil {
+		return x.OpeningPrice
+	}
+	return nil
+}
+
+func (x *PriceHistory) GetPreviousPrice() *OptionalDouble {
+	if x != nil {
+		return x.PreviousPrice
+	}
+	return nil
+}
+
+func (x *PriceHistory) GetChanges() []*PriceChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *PriceHistory) SetOpeningPrice(v *OptionalDouble) {
+	x.OpeningPrice = v
+}
+
+func (x *PriceHistory) SetPreviousPrice(v *OptionalDouble) {
+	x.PreviousPrice = v
+}
+
+func (x *PriceHistory) SetChanges(v []*PriceChange) {
+	x.Changes = v
+}
+
+func (x *PriceHistory) HasOpeningPrice() bool {
+	if x == nil {
+		return false
+	}
+	return x.OpeningPrice != nil
+}
+
+func (x *PriceHistory) HasPreviousPrice() bool {
+	if x == nil {
+		return false
+	}
+	return x.PreviousPrice != nil
+}
+
+func (x *PriceHistory) ClearOpeningPrice() {
+	x.OpeningPrice = nil
+}
+
+func (x *PriceHistory) ClearPreviousPrice() {
+	x.PreviousPrice = nil
+}
+
+type PriceHistory_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	OpeningPrice  *OptionalDouble
+	PreviousPrice *OptionalDouble
+	Changes       []*PriceChange
+}
+
+func (b0 PriceHistory_builder) Build() *PriceHistory {
+	m0 := &PriceHistory{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.OpeningPrice = b.OpeningPrice
+	x.PreviousPrice = b.PreviousPrice
+	x.Changes = b.Changes
+	return m0
+}
+
+// PriceChange models a price a selection moved to
+type PriceChange struct {
+	state         protoimpl.MessageState `protogen:"hybrid.v1"`
+	Price         float64                `protobuf:"fixed64,1,opt,name=Price,proto3" json:"Price,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"` // unix nanoseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceChange) Reset() {
+	*x = PriceChange{}
+	mi := &file_event_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceChange) ProtoMessage() {}
+
+func (x *PriceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_event_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *PriceChange) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PriceChange) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PriceChange) SetPrice(v float64) {
+	x.Price = v
+}
+
+func (x *PriceChange) SetTimestamp(v int64) {
+	x.Timestamp = v
+}
+
+type PriceChange_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Price     float64
+	Timestamp int64
+}
+
+func (b0 PriceChange_builder) Build() *PriceChange {
+	m0 := &PriceChange{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.Price = b.Price
+	x.Timestamp = b.Timestamp
 	return m0
 }
 
@@ -771,7 +1251,7 @@ type OptionalString struct {
 
 func (x *OptionalString) Reset() {
 	*x = OptionalString{}
-	mi := &file_event_proto_msgTypes[5]
+	mi := &file_event_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -783,7 +1263,7 @@ func (x *OptionalString) String() string {
 func (*OptionalString) ProtoMessage() {}
 
 func (x *OptionalString) ProtoReflect() protoreflect.Message {
-	mi := &file_event_proto_msgTypes[5]
+	mi := &file_event_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -842,7 +1322,7 @@ type OptionalDouble struct {
 
 func (x *OptionalDouble) Reset() {
 	*x = OptionalDouble{}
-	mi := &file_event_proto_msgTypes[6]
+	mi := &file_event_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -854,7 +1334,7 @@ func (x *OptionalDouble) String() string {
 func (*OptionalDouble) ProtoMessage() {}
 
 func (x *OptionalDouble) ProtoReflect() protoreflect.Message {
-	mi := &file_event_proto_msgTypes[6]
+	mi := &file_event_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -913,7 +1393,7 @@ type OptionalInt64 struct {
 
 func (x *OptionalInt64) Reset() {
 	*x = OptionalInt64{}
-	mi := &file_event_proto_msgTypes[7]
+	mi := &file_event_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -925,7 +1405,7 @@ func (x *OptionalInt64) String() string {
 func (*OptionalInt64) ProtoMessage() {}
 
 func (x *OptionalInt64) ProtoReflect() protoreflect.Message {
-	mi := &file_event_proto_msgTypes[7]
+	mi := &file_event_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,7 +1461,7 @@ const file_event_proto_rawDesc = "" +
 	"\vevent.proto\x12\x05model\"]\n" +
 	"\x15OptionalBettingStatus\x12*\n" +
 	"\x05Value\x18\x01 \x01(\x0e2\x14.model.BettingStatusR\x05Value\x12\x18\n" +
-	"\aDeleted\x18\x02 \x01(\bR\aDeleted\"\xcd\x02\n" +
+	"\aDeleted\x18\x02 \x01(\bR\aDeleted\"\xbc\x04\n" +
 	"\x05Event\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12)\n" +
 	"\x04Name\x18\x02 \x01(\v2\x15.model.OptionalStringR\x04Name\x122\n" +
@@ -989,13 +1469,20 @@ const file_event_proto_rawDesc = "" +
 	"\rBettingStatus\x18\x04 \x01(\v2\x1c.model.OptionalBettingStatusR\rBettingStatus\x12'\n" +
 	"\aMarkets\x18\x05 \x03(\v2\r.model.MarketR\aMarkets\x127\n" +
 	"\vEventTypeID\x18\x06 \x01(\v2\x15.model.OptionalStringR\vEventTypeID\x12/\n" +
-	"\tSportData\x18\a \x01(\v2\x11.model.SportEventR\tSportData\"\xc2\x01\n" +
+	"\tSportData\x18\a \x01(\v2\x11.model.SportEventR\tSportData\x120\n" +
+	"\bSequence\x18\b \x01(\v2\x14.model.OptionalInt64R\bSequence\x12:\n" +
+	"\rStartActioned\x18\t \x01(\v2\x14.model.OptionalInt64R\rStartActioned\x125\n" +
+	"\n" +
+	"StatusRule\x18\n" +
+	" \x01(\v2\x15.model.OptionalStringR\n" +
+	"StatusRule\x12H\n" +
+	"\x10StatusBeforeRule\x18\v \x01(\v2\x1c.model.OptionalBettingStatusR\x10StatusBeforeRule\"\xc2\x01\n" +
 	"\n" +
 	"SportEvent\x12)\n" +
 	"\x04Name\x18\x01 \x01(\v2\x15.model.OptionalStringR\x04Name\x12-\n" +
 	"\x06Region\x18\x02 \x01(\v2\x15.model.OptionalStringR\x06Region\x12-\n" +
 	"\x06League\x18\x03 \x01(\v2\x15.model.OptionalStringR\x06League\x12+\n" +
-	"\x05Round\x18\x04 \x01(\v2\x15.model.OptionalStringR\x05Round\"\xed\x01\n" +
+	"\x05Round\x18\x04 \x01(\v2\x15.model.OptionalStringR\x05Round\"\xa3\x03\n" +
 	"\x06Market\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12)\n" +
 	"\x04Name\x18\x02 \x01(\v2\x15.model.OptionalStringR\x04Name\x122\n" +
@@ -1003,12 +1490,31 @@ const file_event_proto_rawDesc = "" +
 	"\rBettingStatus\x18\x04 \x01(\v2\x1c.model.OptionalBettingStatusR\rBettingStatus\x120\n" +
 	"\n" +
 	"Selections\x18\x05 \x03(\v2\x10.model.SelectionR\n" +
-	"Selections\"\xb7\x01\n" +
+	"Selections\x123\n" +
+	"\tOverround\x18\x06 \x01(\v2\x15.model.OptionalDoubleR\tOverround\x125\n" +
+	"\n" +
+	"StatusRule\x18\a \x01(\v2\x15.model.OptionalStringR\n" +
+	"StatusRule\x12H\n" +
+	"\x10StatusBeforeRule\x18\b \x01(\v2\x1c.model.OptionalBettingStatusR\x10StatusBeforeRule\"\xdb\x03\n" +
 	"\tSelection\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12)\n" +
 	"\x04Name\x18\x02 \x01(\v2\x15.model.OptionalStringR\x04Name\x12B\n" +
 	"\rBettingStatus\x18\x03 \x01(\v2\x1c.model.OptionalBettingStatusR\rBettingStatus\x12+\n" +
-	"\x05Price\x18\x04 \x01(\v2\x15.model.OptionalDoubleR\x05Price\"@\n" +
+	"\x05Price\x18\x04 \x01(\v2\x15.model.OptionalDoubleR\x05Price\x123\n" +
+	"\tFeedPrice\x18\x05 \x01(\v2\x15.model.OptionalDoubleR\tFeedPrice\x123\n" +
+	"\tPriceFlag\x18\x06 \x01(\v2\x15.model.OptionalStringR\tPriceFlag\x127\n" +
+	"\fPriceHistory\x18\a \x01(\v2\x13.model.PriceHistoryR\fPriceHistory\x125\n" +
+	"\n" +
+	"StatusRule\x18\b \x01(\v2\x15.model.OptionalStringR\n" +
+	"StatusRule\x12H\n" +
+	"\x10StatusBeforeRule\x18\t \x01(\v2\x1c.model.OptionalBettingStatusR\x10StatusBeforeRule\"\xb4\x01\n" +
+	"\fPriceHistory\x129\n" +
+	"\fOpeningPrice\x18\x01 \x01(\v2\x15.model.OptionalDoubleR\fOpeningPrice\x12;\n" +
+	"\rPreviousPrice\x18\x02 \x01(\v2\x15.model.OptionalDoubleR\rPreviousPrice\x12,\n" +
+	"\aChanges\x18\x03 \x03(\v2\x12.model.PriceChangeR\aChanges\"A\n" +
+	"\vPriceChange\x12\x14\n" +
+	"\x05Price\x18\x01 \x01(\x01R\x05Price\x12\x1c\n" +
+	"\tTimestamp\x18\x02 \x01(\x03R\tTimestamp\"@\n" +
 	"\x0eOptionalString\x12\x14\n" +
 	"\x05Value\x18\x01 \x01(\tR\x05Value\x12\x18\n" +
 	"\aDeleted\x18\x02 \x01(\bR\aDeleted\"@\n" +
@@ -1025,7 +1531,7 @@ const file_event_proto_rawDesc = "" +
 	"\rBettingClosed\x10\x03B;Z9git.neds.sh/technology/pricekinetics/tools/codetest/modelb\x06proto3"
 
 var file_event_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_event_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_event_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_event_proto_goTypes = []any{
 	(BettingStatus)(0),            // 0: model.BettingStatus
 	(*OptionalBettingStatus)(nil), // 1: model.OptionalBettingStatus
@@ -1033,34 +1539,51 @@ var file_event_proto_goTypes = []any{
 	(*SportEvent)(nil),            // 3: model.SportEvent
 	(*Market)(nil),                // 4: model.Market
 	(*Selection)(nil),             // 5: model.Selection
-	(*OptionalString)(nil),        // 6: model.OptionalString
-	(*OptionalDouble)(nil),        // 7: model.OptionalDouble
-	(*OptionalInt64)(nil),         // 8: model.OptionalInt64
+	(*PriceHistory)(nil),          // 6: model.PriceHistory
+	(*PriceChange)(nil),           // 7: model.PriceChange
+	(*OptionalString)(nil),        // 8: model.OptionalString
+	(*OptionalDouble)(nil),        // 9: model.OptionalDouble
+	(*OptionalInt64)(nil),         // 10: model.OptionalInt64
 }
 var file_event_proto_depIdxs = []int32{
 	0,  // 0: model.OptionalBettingStatus.Value:type_name -> model.BettingStatus
-	6,  // 1: model.Event.Name:type_name -> model.OptionalString
-	8,  // 2: model.Event.StartTime:type_name -> model.OptionalInt64
+	8,  // 1: model.Event.Name:type_name -> model.OptionalString
+	10, // 2: model.Event.StartTime:type_name -> model.OptionalInt64
 	1,  // 3: model.Event.BettingStatus:type_name -> model.OptionalBettingStatus
 	4,  // 4: model.Event.Markets:type_name -> model.Market
-	6,  // 5: model.Event.EventTypeID:type_name -> model.OptionalString
+	8,  // 5: model.Event.EventTypeID:type_name -> model.OptionalString
 	3,  // 6: model.Event.SportData:type_name -> model.SportEvent
-	6,  // 7: model.SportEvent.Name:type_name -> model.OptionalString
-	6,  // 8: model.SportEvent.Region:type_name -> model.OptionalString
-	6,  // 9: model.SportEvent.League:type_name -> model.OptionalString
-	6,  // 10: model.SportEvent.Round:type_name -> model.OptionalString
-	6,  // 11: model.Market.Name:type_name -> model.OptionalString
-	8,  // 12: model.Market.StartTime:type_name -> model.OptionalInt64
-	1,  // 13: model.Market.BettingStatus:type_name -> model.OptionalBettingStatus
-	5,  // 14: model.Market.Selections:type_name -> model.Selection
-	6,  // 15: model.Selection.Name:type_name -> model.OptionalString
-	1,  // 16: model.Selection.BettingStatus:type_name -> model.OptionalBettingStatus
-	7,  // 17: model.Selection.Price:type_name -> model.OptionalDouble
-	18, // [18:18] is the sub-list for method output_type
-	18, // [18:18] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	10, // 7: model.Event.Sequence:type_name -> model.OptionalInt64
+	10, // 8: model.Event.StartActioned:type_name -> model.OptionalInt64
+	8,  // 9: model.Event.StatusRule:type_name -> model.OptionalString
+	1,  // 10: model.Event.StatusBeforeRule:type_name -> model.OptionalBettingStatus
+	8,  // 11: model.SportEvent.Name:type_name -> model.OptionalString
+	8,  // 12: model.SportEvent.Region:type_name -> model.OptionalString
+	8,  // 13: model.SportEvent.League:type_name -> model.OptionalString
+	8,  // 14: model.SportEvent.Round:type_name -> model.OptionalString
+	8,  // 15: model.Market.Name:type_name -> model.OptionalString
+	10, // 16: model.Market.StartTime:type_name -> model.OptionalInt64
+	1,  // 17: model.Market.BettingStatus:type_name -> model.OptionalBettingStatus
+	5,  // 18: model.Market.Selections:type_name -> model.Selection
+	9,  // 19: model.Market.Overround:type_name -> model.OptionalDouble
+	8,  // 20: model.Market.StatusRule:type_name -> model.OptionalString
+	1,  // 21: model.Market.StatusBeforeRule:type_name -> model.OptionalBettingStatus
+	8,  // 22: model.Selection.Name:type_name -> model.OptionalString
+	1,  // 23: model.Selection.BettingStatus:type_name -> model.OptionalBettingStatus
+	9,  // 24: model.Selection.Price:type_name -> model.OptionalDouble
+	9,  // 25: model.Selection.FeedPrice:type_name -> model.OptionalDouble
+	8,  // 26: model.Selection.PriceFlag:type_name -> model.OptionalString
+	6,  // 27: model.Selection.PriceHistory:type_name -> model.PriceHistory
+	8,  // 28: model.Selection.StatusRule:type_name -> model.OptionalString
+	1,  // 29: model.Selection.StatusBeforeRule:type_name -> model.OptionalBettingStatus
+	9,  // 30: model.PriceHistory.OpeningPrice:type_name -> model.OptionalDouble
+	9,  // 31: model.PriceHistory.PreviousPrice:type_name -> model.OptionalDouble
+	7,  // 32: model.PriceHistory.Changes:type_name -> model.PriceChange
+	33, // [33:33] is the sub-list for method output_type
+	33, // [33:33] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_event_proto_init() }
@@ -1074,7 +1597,7 @@ func file_event_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_event_proto_rawDesc), len(file_event_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   8,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},